@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// benchPositions are the positions the bench searches, from the opening to
+// the endgame, always the same for node counts to compare
+var benchPositions = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"r1bq1rk1/pp2bppp/2n1pn2/3p4/2PP4/2N1PN2/PP3PPP/R2QKB1R w KQ - 0 8",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"2r3k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1",
+}
+
+// defaultBenchDepth is how deep the bench searches each position by default
+const defaultBenchDepth = 3
+
+// Bench searches every bench position to given depth with given heuristics,
+// each time from an empty transposition table, and returns the nodes
+// searched per position
+func Bench(depth int, heuristics Heuristic) []int {
+	nodes := []int{}
+	engine := NewEngine(depth, 0, defaultHashSize, time.Now)
+	engine.heuristics = heuristics
+	for _, fen := range benchPositions {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			panic(err)
+		}
+		engine.NewGame()
+		nodes = append(nodes, engine.Search(game, 0).nodes)
+	}
+	return nodes
+}
+
+// RunBench runs the bench command, e.g. "chess bench [depth]"
+// It searches the bench positions with no heuristic to order moves, then adds
+// them one by one, printing the nodes searched each time, so that fewer nodes
+// show how much a heuristic prunes.
+func RunBench(args []string, output io.Writer) error {
+	depth := defaultBenchDepth
+	if len(args) > 0 {
+		var err error
+		depth, err = strconv.Atoi(args[0])
+		if err != nil || depth < 1 {
+			return fmt.Errorf("invalid bench depth %q, must be a positive number", args[0])
+		}
+	}
+
+	fmt.Fprintf(output, "%-16s", "heuristics")
+	for i := range benchPositions {
+		fmt.Fprintf(output, " %9s", fmt.Sprintf("#%d", i+1))
+	}
+	fmt.Fprintf(output, " %10s\n", "total")
+	used := Heuristic(0)
+	for i := -1; i < len(heuristics); i++ {
+		name := "none"
+		if i >= 0 {
+			used |= heuristics[i]
+			name = "+ " + GetHeuristicName(heuristics[i])
+		}
+		fmt.Fprintf(output, "%-16s", name)
+		total := 0
+		for _, nodes := range Bench(depth, used) {
+			fmt.Fprintf(output, " %9d", nodes)
+			total += nodes
+		}
+		fmt.Fprintf(output, " %10d\n", total)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// Bitboard is a set of squares, one bit per square
+// Bits follow the order of Position cells, so square row*8 + col is bit
+// row*8 + col.
+type Bitboard uint64
+
+// Magic finds the attacks of a sliding piece from one square in a table
+// The occupancy of the squares that can block it is multiplied by a magic
+// number, whose top bits then index every blocker arrangement perfectly.
+type Magic struct {
+	mask    Bitboard
+	magic   Bitboard
+	shift   uint
+	attacks []Bitboard
+}
+
+// knightAttacks are the squares a Knight attacks from each square
+var knightAttacks [64]Bitboard
+
+// kingAttacks are the squares a King attacks from each square
+var kingAttacks [64]Bitboard
+
+// pawnAttacks are the squares a pawn of each team attacks from each square
+var pawnAttacks [2][64]Bitboard
+
+// rookMagics and bishopMagics find sliding attacks from each square
+var rookMagics [64]Magic
+var bishopMagics [64]Magic
+
+// magicSeed is the seed FindMagic searched rookMagicNumbers and
+// bishopMagicNumbers with, squares in order and a Rook before a Bishop
+const magicSeed = 2020
+
+// rookMagicNumbers are the magic numbers of a Rook on each square
+var rookMagicNumbers = [64]Bitboard{
+	0x00800010208c4000, 0x0040002000100040, 0x4080082000801000, 0x0200100840060020,
+	0x0200100802002004, 0x0500040001000208, 0x0480010002000080, 0x8100044130820100,
+	0x0000800030804000, 0x1019002100400680, 0x4242002018408200, 0x8001802801801000,
+	0x4812002004081200, 0x0300808002000400, 0x0015000200090014, 0x000600084086091c,
+	0x0000818000224004, 0x083002c020004004, 0x4100848010042000, 0x8049010008201000,
+	0x0000808008000400, 0x0401010002080400, 0x8440840010010882, 0x000102000c006081,
+	0x8080004040002000, 0x0000200140005000, 0x4000200100104100, 0x0858082100100100,
+	0x1800050100100800, 0x0010040080020080, 0x1001020400010810, 0x00142402000040a1,
+	0x0080004000402000, 0x0d10082004c04000, 0x0000421482002200, 0x1100201001000900,
+	0x0008800400800800, 0x0001000401000802, 0x0003000c51001200, 0x0012090892001044,
+	0x2080002000504000, 0x0000500020004004, 0x0200402001010010, 0x4000100008008080,
+	0x200a000820060010, 0x0200040002008080, 0x0120100248840001, 0x4881000aaa430002,
+	0xb001003480044100, 0x0040004426930100, 0x8000801000200080, 0x0400100080080480,
+	0x00c5000800100500, 0x0044000200048080, 0x0080025001280400, 0x002800512c008200,
+	0x8202810020401602, 0x9012008040210012, 0x4022001520088042, 0x0841002009051001,
+	0x5206002050081c0a, 0x2009000208040001, 0x2084210802009004, 0x5208028504052042,
+}
+
+// bishopMagicNumbers are the magic numbers of a Bishop on each square
+var bishopMagicNumbers = [64]Bitboard{
+	0x104001280101002a, 0x2048100410982050, 0x0042208200822000, 0x00080a0021040600,
+	0x0801104100003402, 0x0032024221000081, 0x0092110420040020, 0x0080820110018402,
+	0x1292400548208100, 0x2a8020094c088080, 0x1080100084811450, 0x0000c82147422022,
+	0x0201140422001000, 0x024018880440000c, 0x2410404110482001, 0x0000404202102242,
+	0x10040c5004102444, 0x0220044808408880, 0x0a90050104008511, 0x0454000802460820,
+	0x0045000090400010, 0x0401000808821080, 0x1002800406085200, 0x0049084280480a6a,
+	0x008250626020020c, 0xa02a110208104880, 0x4082011202080200, 0x0022002002008200,
+	0x0121010000104000, 0x4090008001080940, 0x3060920000821000, 0x525220c002840480,
+	0x0630100400104403, 0x0812020200107040, 0x010040220010041a, 0x0090208020080200,
+	0x0402020200c40084, 0x0211020204188800, 0x2081040900208802, 0x0881205204008200,
+	0x00020120e102080a, 0x010042122100100a, 0x0802020424000a03, 0x0a00134200841800,
+	0xa940041008801c08, 0x2202009000800900, 0x0010c20a04022040, 0x2650490458820100,
+	0x00004206a0600000, 0x2511042882080020, 0x0000210c01041040, 0x0320e00105881440,
+	0x2300011002088208, 0x10006002420a0006, 0x018802e802440000, 0x0822080801094600,
+	0x0010402805105020, 0x8204118488019000, 0x0804400200822104, 0x1000042002050418,
+	0x10c0000210221200, 0x8200022024101882, 0x0020045010010102, 0x2082041024110020,
+}
+
+func init() {
+	for square := 0; square < 64; square++ {
+		origin := Location{row: square / 8, col: square % 8}
+		knightAttacks[square] = GetStepAttacks(origin, knightJumps)
+		kingAttacks[square] = GetStepAttacks(origin, kingSteps)
+		pawnAttacks[WHITE][square] = GetStepAttacks(origin, []Direction{{GetPawnDirection(WHITE), -1}, {GetPawnDirection(WHITE), 1}})
+		pawnAttacks[BLACK][square] = GetStepAttacks(origin, []Direction{{GetPawnDirection(BLACK), -1}, {GetPawnDirection(BLACK), 1}})
+	}
+
+	for square := 0; square < 64; square++ {
+		origin := Location{row: square / 8, col: square % 8}
+		var isRookMagic, isBishopMagic bool
+		rookMagics[square], isRookMagic = NewMagic(origin, straightDirections, rookMagicNumbers[square])
+		bishopMagics[square], isBishopMagic = NewMagic(origin, diagonalDirections, bishopMagicNumbers[square])
+		if !isRookMagic || !isBishopMagic {
+			panic("magic number tables are broken")
+		}
+	}
+}
+
+// NewBitboard returns the Bitboard of a single location
+func NewBitboard(location Location) Bitboard {
+	return 1 << uint(location.row*8+location.col)
+}
+
+// Has returns whether the square of given index is in the set
+func (bb Bitboard) Has(square int) bool {
+	return bb&(1<<uint(square)) != 0
+}
+
+// Count returns how many squares are in the set
+func (bb Bitboard) Count() int {
+	return bits.OnesCount64(uint64(bb))
+}
+
+// First returns the index of the lowest square in the set, 64 when empty
+func (bb Bitboard) First() int {
+	return bits.TrailingZeros64(uint64(bb))
+}
+
+// PopFirst removes the lowest square from the set and returns its index
+func (bb *Bitboard) PopFirst() int {
+	square := bb.First()
+	*bb &= *bb - 1
+	return square
+}
+
+// GetLocations returns the locations of the squares in the set, lowest first
+func (bb Bitboard) GetLocations() []Location {
+	locations := []Location{}
+	for bb != 0 {
+		square := bb.PopFirst()
+		locations = append(locations, Location{row: square / 8, col: square % 8})
+	}
+	return locations
+}
+
+// GetStepAttacks returns the squares reached with a single step from origin
+// in each of the directions
+func GetStepAttacks(origin Location, directions []Direction) Bitboard {
+	attacks := Bitboard(0)
+	for _, direction := range directions {
+		row := origin.row + direction.row
+		col := origin.col + direction.col
+		if IsLocationValid(row, col) {
+			attacks |= NewBitboard(Location{row: row, col: col})
+		}
+	}
+	return attacks
+}
+
+// GetRayAttacks returns the squares a sliding piece attacks from origin,
+// walking each direction up to the edge of the board or the first occupied
+// square, which is included
+func GetRayAttacks(origin Location, directions []Direction, occupied Bitboard) Bitboard {
+	attacks := Bitboard(0)
+	for _, direction := range directions {
+		row := origin.row + direction.row
+		col := origin.col + direction.col
+		for IsLocationValid(row, col) {
+			square := NewBitboard(Location{row: row, col: col})
+			attacks |= square
+			if occupied&square != 0 {
+				break
+			}
+			row += direction.row
+			col += direction.col
+		}
+	}
+	return attacks
+}
+
+// GetRayMask returns the squares whose occupancy matters to a sliding piece
+// at origin, i.e. its rays without the squares on the edge of the board
+func GetRayMask(origin Location, directions []Direction) Bitboard {
+	mask := Bitboard(0)
+	for _, direction := range directions {
+		row := origin.row + direction.row
+		col := origin.col + direction.col
+		for IsLocationValid(row+direction.row, col+direction.col) {
+			mask |= NewBitboard(Location{row: row, col: col})
+			row += direction.row
+			col += direction.col
+		}
+	}
+	return mask
+}
+
+// GetOccupancies returns every arrangement of blockers on the mask of a
+// sliding piece at origin, along with the attacks each one allows
+func GetOccupancies(origin Location, directions []Direction) ([]Bitboard, []Bitboard) {
+	mask := GetRayMask(origin, directions)
+	size := 1 << uint(mask.Count())
+	occupancies := make([]Bitboard, 0, size)
+	references := make([]Bitboard, 0, size)
+	occupied := Bitboard(0)
+	for {
+		occupancies = append(occupancies, occupied)
+		references = append(references, GetRayAttacks(origin, directions, occupied))
+		occupied = (occupied - mask) & mask
+		if occupied == 0 {
+			break
+		}
+	}
+	return occupancies, references
+}
+
+// NewMagic returns the Magic of a sliding piece at origin, moving in given
+// directions, with its attack table filled in
+// It also returns whether the number is magic, i.e. no two arrangements of
+// blockers that allow different attacks share an index.
+func NewMagic(origin Location, directions []Direction, magic Bitboard) (Magic, bool) {
+	mask := GetRayMask(origin, directions)
+	m := Magic{
+		mask:    mask,
+		magic:   magic,
+		shift:   uint(64 - mask.Count()),
+		attacks: make([]Bitboard, 1<<uint(mask.Count())),
+	}
+	occupancies, references := GetOccupancies(origin, directions)
+	for i, occupancy := range occupancies {
+		index := m.GetIndex(occupancy)
+		if m.attacks[index] != 0 && m.attacks[index] != references[i] {
+			return m, false
+		}
+		m.attacks[index] = references[i]
+	}
+	return m, true
+}
+
+// FindMagic searches a magic number for a sliding piece at origin, moving in
+// given directions, by trying random ones until one indexes perfectly
+// It is how the magic number tables were made, too slow to run every start.
+func FindMagic(origin Location, directions []Direction, random *rand.Rand) Bitboard {
+	mask := GetRayMask(origin, directions)
+	shift := uint(64 - mask.Count())
+	occupancies, references := GetOccupancies(origin, directions)
+	attacks := make([]Bitboard, 1<<uint(mask.Count()))
+	used := make([]int, len(attacks))
+	for attempt := 1; ; attempt++ {
+		// sparse numbers make good magics far more often
+		magic := Bitboard(random.Uint64() & random.Uint64() & random.Uint64())
+		if ((mask * magic) >> 56).Count() < 6 {
+			continue
+		}
+		isMagic := true
+		for i, occupancy := range occupancies {
+			index := int((occupancy * magic) >> shift)
+			if used[index] != attempt {
+				used[index] = attempt
+				attacks[index] = references[i]
+			} else if attacks[index] != references[i] {
+				isMagic = false
+				break
+			}
+		}
+		if isMagic {
+			return magic
+		}
+	}
+}
+
+// GetIndex returns where the attacks for given occupancy are in the table
+func (m Magic) GetIndex(occupied Bitboard) int {
+	return int(((occupied & m.mask) * m.magic) >> m.shift)
+}
+
+// GetRookAttacks returns the squares a Rook attacks from square
+func GetRookAttacks(square int, occupied Bitboard) Bitboard {
+	m := &rookMagics[square]
+	return m.attacks[m.GetIndex(occupied)]
+}
+
+// GetBishopAttacks returns the squares a Bishop attacks from square
+func GetBishopAttacks(square int, occupied Bitboard) Bitboard {
+	m := &bishopMagics[square]
+	return m.attacks[m.GetIndex(occupied)]
+}
+
+// GetQueenAttacks returns the squares a Queen attacks from square
+func GetQueenAttacks(square int, occupied Bitboard) Bitboard {
+	return GetRookAttacks(square, occupied) | GetBishopAttacks(square, occupied)
+}
+
+// GetPieceAttacks returns the squares a piece of team attacks from square
+func GetPieceAttacks(piece Piece, team Team, square int, occupied Bitboard) Bitboard {
+	switch piece {
+	case PAWN:
+		return pawnAttacks[team][square]
+	case KNIGHT:
+		return knightAttacks[square]
+	case BISHOP:
+		return GetBishopAttacks(square, occupied)
+	case ROOK:
+		return GetRookAttacks(square, occupied)
+	case QUEEN:
+		return GetQueenAttacks(square, occupied)
+	}
+	return kingAttacks[square]
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestStepAttacks(t *testing.T) {
+	if knightAttacks[0].Count() != 2 || knightAttacks[27].Count() != 8 {
+		t.Errorf("Knight attacks %d squares from a8 and %d from d5", knightAttacks[0].Count(), knightAttacks[27].Count())
+	}
+	if kingAttacks[63].Count() != 3 || kingAttacks[36].Count() != 8 {
+		t.Errorf("King attacks %d squares from h1 and %d from e4", kingAttacks[63].Count(), kingAttacks[36].Count())
+	}
+
+	// a white pawn on e2 attacks d3 and f3, a black one on e7 attacks d6 and f6
+	e2 := NewBitboard(Location{row: 6, col: 4}).First()
+	if pawnAttacks[WHITE][e2] != NewBitboard(Location{row: 5, col: 3})|NewBitboard(Location{row: 5, col: 5}) {
+		t.Error("white pawn attacks the wrong squares")
+	}
+	e7 := NewBitboard(Location{row: 1, col: 4}).First()
+	if pawnAttacks[BLACK][e7] != NewBitboard(Location{row: 2, col: 3})|NewBitboard(Location{row: 2, col: 5}) {
+		t.Error("black pawn attacks the wrong squares")
+	}
+}
+
+func TestMagicAttacks(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for square := 0; square < 64; square++ {
+		origin := Location{row: square / 8, col: square % 8}
+		for i := 0; i < 100; i++ {
+			occupied := Bitboard(random.Uint64() & random.Uint64())
+			if GetRookAttacks(square, occupied) != GetRayAttacks(origin, straightDirections, occupied) {
+				t.Fatalf("Rook attacks from %d differ from walking its rays", square)
+			}
+			if GetBishopAttacks(square, occupied) != GetRayAttacks(origin, diagonalDirections, occupied) {
+				t.Fatalf("Bishop attacks from %d differ from walking its rays", square)
+			}
+		}
+	}
+}
+
+func TestFindMagic(t *testing.T) {
+	origin := Location{row: 4, col: 2}
+	magic := FindMagic(origin, diagonalDirections, rand.New(rand.NewSource(magicSeed)))
+	if _, isMagic := NewMagic(origin, diagonalDirections, magic); !isMagic {
+		t.Error("found a Bishop magic that does not index perfectly")
+	}
+}
+
+func TestAttackers(t *testing.T) {
+	game, err := NewGameFromFEN("4k3/8/8/3p4/1N2B3/8/8/3RK3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d5 := NewBitboard(Location{row: 3, col: 3}).First()
+	attackers := game.position.GetAttackers(d5, WHITE, game.position.GetOccupied())
+	if attackers.Count() != 3 {
+		t.Errorf("expected Knight, Bishop and Rook to attack d5, got %v", attackers.GetLocations())
+	}
+	if !game.position.IsAttacked(NewBitboard(Location{row: 4, col: 2}).First(), BLACK) {
+		t.Error("black pawn on d5 does not attack c4")
+	}
+	if game.position.IsAttacked(NewBitboard(Location{row: 4, col: 3}).First(), BLACK) {
+		t.Error("black pawn on d5 attacks d4 straight ahead")
+	}
+}
@@ -57,26 +57,19 @@ func IsLocationValid(row int, col int) bool {
 // Execute applies a move to the board
 // Essentially, it is the move of a piece on the board.
 func (b *Board) Execute(m Move) {
-	// change piece position on the board
-	oldLocation := m.GetLocation(BEFORE)
-	newLocation := m.GetLocation(AFTER)
-	square := b.GetSquare(m, BEFORE)
-
-	if !IsLocationValid(newLocation.row, newLocation.col) {
-		errorMsg := fmt.Sprintf("move destination location (%d:%d) is invalid", newLocation.row, newLocation.col)
-		panic(errorMsg)
-	}
-	b[newLocation.row][newLocation.col] = GetTeamName(m.team, SYMBOL) + " " + GetPieceName(square.piece, SYMBOL)
-
-	if !IsLocationValid(oldLocation.row, oldLocation.col) {
-		errorMsg := fmt.Sprintf("move origin location (%d:%d) is invalid", oldLocation.row, oldLocation.col)
-		panic(errorMsg)
-	}
-	b[oldLocation.row][oldLocation.col] = "   "
+	p := NewPositionFromBoard(*b)
+	p.Execute(m)
+	*b = p.AsBoard()
 }
 
 // Render prints the board in stdout
 func (b *Board) Render() {
+	b.RenderBeside([8]string{})
+}
+
+// RenderBeside prints the board in stdout, with given notes beside its rows,
+// e.g. the time each team has left beside its home row
+func (b *Board) RenderBeside(notes [8]string) {
 	fmt.Println() // to breathe
 	fmt.Println("   |  a  |  b  |  c  |  d  |  e  |  f  |  g  |  h  |")
 	fmt.Println(" - +-----+-----+-----+-----+-----+-----+-----+-----+")
@@ -87,6 +80,9 @@ func (b *Board) Render() {
 			cell := b[i][j]
 			fmt.Printf(" %s |", cell)
 		}
+		if notes[i] != "" {
+			fmt.Printf("  %s", notes[i])
+		}
 		fmt.Println()
 	}
 
@@ -97,92 +93,17 @@ func (b *Board) Render() {
 // GetSquare returns the part piece that is to be moved, either BEFORE or AFTER
 func (b Board) GetSquare(m Move, part Part) Square {
 	location := m.GetLocation(part)
-	content := b[location.row][location.col]
-
-	color := []rune(content)[0]
-	team := NEITHER
-	if color == '●' {
-		team = BLACK
-	} else if color == '○' {
-		team = WHITE
-	}
-
-	pieceRune := []rune(content)[2]
-	isEmpty := false
-	if pieceRune == ' ' {
-		isEmpty = true
-	}
-	piece := PAWN
-	pieceSymbols := map[rune]Piece{
-		'P': PAWN,
-		'R': ROOK,
-		'K': KNIGHT,
-		'B': BISHOP,
-		'Q': QUEEN,
-		'G': KING,
-	}
-	piece = pieceSymbols[pieceRune]
-
-	square := Square{
-		team:    team,
-		piece:   piece,
-		isEmpty: isEmpty,
-	}
-
-	return square
+	return b.ParseSquare(location.row, location.col)
 }
 
 // ParseSquare returns square based on indexes
 func (b Board) ParseSquare(row int, col int) Square {
-	content := b[row][col]
-
-	color := []rune(content)[0]
-	team := NEITHER
-	if color == '●' {
-		team = BLACK
-	} else if color == '○' {
-		team = WHITE
-	}
-
-	pieceRune := []rune(content)[2]
-	isEmpty := false
-	if pieceRune == ' ' {
-		isEmpty = true
-	}
-	piece := PAWN
-	pieceSymbols := map[rune]Piece{
-		'P': PAWN,
-		'R': ROOK,
-		'K': KNIGHT,
-		'B': BISHOP,
-		'Q': QUEEN,
-		'G': KING,
-	}
-	piece = pieceSymbols[pieceRune]
-
-	square := Square{
-		team:    team,
-		piece:   piece,
-		isEmpty: isEmpty,
-	}
-
-	return square
+	return GetCellFromSymbol(b[row][col]).AsSquare()
 }
 
 // FindKing returns the square of the King on current board
 func (b Board) FindKing(team Team) Location {
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			square := b.ParseSquare(i, j)
-			if square.team == team && square.piece == KING {
-				return Location{
-					row: i,
-					col: j,
-				}
-			}
-		}
-	}
-	return Location{}
+	return NewPositionFromBoard(b).FindKing(team)
 }
 
 // LoadData loads all data into board from another board
@@ -26,3 +26,35 @@ func TestBoardPawn(t *testing.T) {
 		t.Error("white pawn did not move into e5")
 	}
 }
+
+func TestBoardCastling(t *testing.T) {
+	board := Board{
+		{"● R", "● K", "● B", "● Q", "● G", "● B", "● K", "● R"},
+		{"● P", "● P", "● P", "● P", "● P", "● P", "● P", "● P"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P"},
+		{"○ R", "   ", "   ", "   ", "○ G", "○ B", "○ K", "○ R"},
+	}
+
+	// create move
+	turn := WHITE
+	command := "e8 c8"
+	move, _, _, _ := NewMove(board, turn, command)
+
+	// execute move
+	board.Execute(move)
+
+	// verify both King and Rook moved
+	if board[7][2] != "○ G" {
+		t.Error("white king did not move into c8")
+	}
+	if board[7][3] != "○ R" {
+		t.Error("white rook did not move into d8")
+	}
+	if board[7][0] != "   " || board[7][4] != "   " {
+		t.Error("white king or rook did not move out of their squares")
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bonus is how a stage of a time control gives time back for each move
+type Bonus int
+
+const (
+	// INCREMENT adds the bonus to the clock after every move, e.g. "5+3"
+	INCREMENT Bonus = iota
+	// SIMPLEDELAY lets the clock wait for the bonus before it runs, e.g. "5d3"
+	SIMPLEDELAY
+	// BRONSTEIN gives back the time a move took, up to the bonus, e.g. "5b3"
+	BRONSTEIN
+)
+
+// Stage is a period of a time control, e.g. 40 moves in 90 minutes
+type Stage struct {
+	// moves is how many moves the stage lasts, 0 for the rest of the game
+	moves int
+	base  time.Duration
+	bonus time.Duration
+	kind  Bonus
+}
+
+// TimeControl is the stages of time a game is played with, in order
+// The last stage repeats when it lasts a number of moves.
+type TimeControl []Stage
+
+// bonusKinds are the separators of base and bonus time of a stage
+var bonusKinds = map[rune]Bonus{
+	'+': INCREMENT,
+	'd': SIMPLEDELAY,
+	'b': BRONSTEIN,
+}
+
+// ParseTimeControl parses a time control given as stages separated by ":"
+// Each stage is an optional number of moves followed by "/", the base time in
+// minutes, and optionally "+", "d" or "b" followed by the increment, simple
+// delay or Bronstein delay in seconds, e.g. "5+3" or "40/90+30:30+30".
+func ParseTimeControl(text string) (TimeControl, error) {
+	control := TimeControl{}
+	for i, field := range strings.Split(text, ":") {
+		stage := Stage{}
+		if slash := strings.Index(field, "/"); slash >= 0 {
+			moves, err := strconv.Atoi(field[:slash])
+			if err != nil || moves < 1 {
+				return nil, fmt.Errorf("invalid time control: stage %d must start with a number of moves, got %q", i+1, field[:slash])
+			}
+			stage.moves = moves
+			field = field[slash+1:]
+		}
+		base := field
+		if separator := strings.IndexAny(field, "+db"); separator >= 0 {
+			base = field[:separator]
+			stage.kind = bonusKinds[rune(field[separator])]
+			bonus, isValid := ParseDuration(field[separator+1:], time.Second)
+			if !isValid {
+				return nil, fmt.Errorf("invalid time control: stage %d must end with a bonus in seconds, got %q", i+1, field[separator+1:])
+			}
+			stage.bonus = bonus
+		}
+		duration, isValid := ParseDuration(base, time.Minute)
+		if !isValid || duration == 0 {
+			return nil, fmt.Errorf("invalid time control: stage %d must have a base time in minutes, got %q", i+1, base)
+		}
+		stage.base = duration
+		control = append(control, stage)
+	}
+	return control, nil
+}
+
+// ParseDuration parses a number of given unit, e.g. "1.5" minutes, and returns
+// whether it is a valid duration
+// Negative numbers, NaN, infinities and numbers too large for a Duration are
+// not valid.
+func ParseDuration(text string, unit time.Duration) (time.Duration, bool) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, false
+	}
+	if value < 0 || value >= math.MaxInt64/float64(unit) {
+		return 0, false
+	}
+	return time.Duration(value * float64(unit)), true
+}
+
+// GetStage returns the stage a team plays its next move in, after given number
+// of moves
+// It also returns whether the move starts that stage, and thus adds its base.
+func (tc TimeControl) GetStage(moves int) (Stage, bool) {
+	played := 0
+	for _, stage := range tc {
+		if stage.moves == 0 || moves < played+stage.moves {
+			return stage, moves == played && moves > 0
+		}
+		played += stage.moves
+	}
+
+	// the last stage lasts a number of moves, so it repeats
+	last := tc[len(tc)-1]
+	return last, (moves-played)%last.moves == 0
+}
+
+// Clock is a chess clock, keeping the remaining time of both teams
+// Only the clock of the team to move runs. Time is read from now, which tests
+// replace to control it.
+type Clock struct {
+	control   TimeControl
+	remaining [2]time.Duration
+	moves     [2]int
+	running   Team
+	started   time.Time
+	now       func() time.Time
+}
+
+// ClockUndo is what a press of the clock gave a team, for a takeback to take
+// it away again
+type ClockUndo struct {
+	team Team
+	// gained is the bonus and the base time of a new stage the move earned
+	gained time.Duration
+}
+
+// NewClock returns a stopped clock with the time of the first stage on both
+// sides, reading time from now, e.g. time.Now
+func NewClock(control TimeControl, now func() time.Time) *Clock {
+	return &Clock{
+		control:   control,
+		remaining: [2]time.Duration{control[0].base, control[0].base},
+		running:   NEITHER,
+		now:       now,
+	}
+}
+
+// Start stops the clock that runs, if any, and starts the one of given team
+// The time used so far is taken off, without any bonus, e.g. on a takeback.
+func (c *Clock) Start(team Team) {
+	if c.running != NEITHER {
+		c.remaining[c.running] -= c.GetUsed(c.now().Sub(c.started))
+	}
+	c.running = team
+	c.started = c.now()
+}
+
+// Press ends the move of the team whose clock runs and starts the enemy one
+// The time the move took is taken off, then any bonus given back, and the
+// base time of the next stage added when the move completes a stage.
+// It returns the ClockUndo that TakeBack needs to take the move back.
+func (c *Clock) Press() ClockUndo {
+	team := c.running
+	if team == NEITHER {
+		return ClockUndo{team: NEITHER}
+	}
+	stage, _ := c.control.GetStage(c.moves[team])
+	elapsed := c.now().Sub(c.started)
+	c.remaining[team] -= c.GetUsed(elapsed)
+	u := ClockUndo{team: team}
+	if stage.kind == INCREMENT {
+		u.gained = stage.bonus
+	} else if stage.kind == BRONSTEIN {
+		if elapsed < stage.bonus {
+			u.gained = elapsed
+		} else {
+			u.gained = stage.bonus
+		}
+	}
+
+	c.moves[team]++
+	if next, isNew := c.control.GetStage(c.moves[team]); isNew {
+		u.gained += next.base
+	}
+	c.remaining[team] += u.gained
+
+	enemy := WHITE
+	if team == WHITE {
+		enemy = BLACK
+	}
+	c.running = enemy
+	c.started = c.now()
+	return u
+}
+
+// TakeBack takes back a move pressed with given ClockUndo, taking away what
+// it gained and counting it no more towards its stage
+// The time the move took stays used; Start then runs the clock of the team to
+// move again.
+func (c *Clock) TakeBack(u ClockUndo) {
+	if u.team == NEITHER {
+		return
+	}
+	c.moves[u.team]--
+	c.remaining[u.team] -= u.gained
+}
+
+// GetUsed returns how much of its time the running team used, given how long
+// its clock has run
+// A simple delay passes before the clock starts counting down.
+func (c *Clock) GetUsed(elapsed time.Duration) time.Duration {
+	stage, _ := c.control.GetStage(c.moves[c.running])
+	if stage.kind == SIMPLEDELAY {
+		elapsed -= stage.bonus
+		if elapsed < 0 {
+			return 0
+		}
+	}
+	return elapsed
+}
+
+// GetRemaining returns the time given team has left, as of now
+func (c *Clock) GetRemaining(team Team) time.Duration {
+	remaining := c.remaining[team]
+	if team == c.running {
+		remaining -= c.GetUsed(c.now().Sub(c.started))
+	}
+	return remaining
+}
+
+// IsFlagged returns whether given team has run out of time
+func (c *Clock) IsFlagged(team Team) bool {
+	return c.GetRemaining(team) <= 0
+}
+
+// FormatClock returns a remaining time the way a clock shows it, e.g. "4:57",
+// "1:30:00", or "0:09.5" with tenths of a second when little is left
+func FormatClock(remaining time.Duration) string {
+	if remaining < 0 {
+		remaining = 0
+	}
+	if remaining < 20*time.Second {
+		tenths := remaining / (100 * time.Millisecond)
+		return fmt.Sprintf("0:%02d.%d", tenths/10, tenths%10)
+	}
+	seconds := int(remaining / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
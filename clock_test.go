@@ -0,0 +1,156 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeTime is a time source that only moves when told to
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.now
+}
+
+// playClock presses the clock once per given move duration, starting white
+func playClock(control TimeControl, durations ...time.Duration) *Clock {
+	source := &fakeTime{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	clock := NewClock(control, source.Now)
+	clock.Start(WHITE)
+	for _, duration := range durations {
+		source.now = source.now.Add(duration)
+		clock.Press()
+	}
+	return clock
+}
+
+func TestParseTimeControl(t *testing.T) {
+	control, err := ParseTimeControl("40/90+30:30d5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := TimeControl{
+		{moves: 40, base: 90 * time.Minute, bonus: 30 * time.Second, kind: INCREMENT},
+		{base: 30 * time.Minute, bonus: 5 * time.Second, kind: SIMPLEDELAY},
+	}
+	if len(control) != len(expected) || control[0] != expected[0] || control[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, control)
+	}
+
+	for _, text := range []string{"", "5+", "x+3", "0/5+3", "5+3:", "-5", "NaN", "Inf+3", "1e9+1", "5+NaN", "5+Inf", "5+1e300"} {
+		if _, err := ParseTimeControl(text); err == nil {
+			t.Errorf("%q: expected an invalid time control", text)
+		}
+	}
+}
+
+func TestClockBonus(t *testing.T) {
+	for text, expected := range map[string]time.Duration{
+		// 10s are used and 3s added
+		"5+3": 4*time.Minute + 53*time.Second,
+		// 3s pass before the clock runs down, so 7s are used
+		"5d3": 4*time.Minute + 53*time.Second,
+		// 3s of the 10s used are given back
+		"5b3": 4*time.Minute + 53*time.Second,
+		// a delay longer than the move uses no time at all
+		"5d15": 5 * time.Minute,
+		"5b15": 5 * time.Minute,
+	} {
+		control, err := ParseTimeControl(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock := playClock(control, 10*time.Second)
+		if clock.GetRemaining(WHITE) != expected {
+			t.Errorf("%s: expected %s left, got %s", text, expected, clock.GetRemaining(WHITE))
+		}
+		if clock.GetRemaining(BLACK) != 5*time.Minute {
+			t.Errorf("%s: black clock ran while white moved", text)
+		}
+	}
+}
+
+func TestClockStages(t *testing.T) {
+	control, err := ParseTimeControl("2/10:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := playClock(control, time.Minute, time.Minute, time.Minute)
+	if clock.GetRemaining(WHITE) != 13*time.Minute {
+		t.Errorf("expected white to get the second stage after 2 moves, got %s left", clock.GetRemaining(WHITE))
+	}
+	if clock.GetRemaining(BLACK) != 9*time.Minute {
+		t.Errorf("expected black to stay in the first stage after 1 move, got %s left", clock.GetRemaining(BLACK))
+	}
+
+	// a last stage with a number of moves repeats
+	control, err = ParseTimeControl("1/10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock = playClock(control, time.Minute, 0, time.Minute)
+	if clock.GetRemaining(WHITE) != 28*time.Minute {
+		t.Errorf("expected the first stage to repeat, got %s left", clock.GetRemaining(WHITE))
+	}
+}
+
+func TestClockTakeBack(t *testing.T) {
+	control, err := ParseTimeControl("2/10+30:5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &fakeTime{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	clock := NewClock(control, source.Now)
+	clock.Start(WHITE)
+	undos := []ClockUndo{}
+	for _, duration := range []time.Duration{time.Minute, 0, time.Minute} {
+		source.now = source.now.Add(duration)
+		undos = append(undos, clock.Press())
+	}
+
+	// white takes back the move that completed the first stage, losing its
+	// increment and the second stage, but not the minute it took
+	clock.Start(BLACK)
+	clock.TakeBack(undos[2])
+	clock.Start(WHITE)
+	if clock.GetRemaining(WHITE) != 8*time.Minute+30*time.Second {
+		t.Errorf("expected the bonus and stage to be taken back, got %s left", clock.GetRemaining(WHITE))
+	}
+
+	// played again, the move completes the first stage again
+	source.now = source.now.Add(time.Minute)
+	clock.Press()
+	if clock.GetRemaining(WHITE) != 13*time.Minute {
+		t.Errorf("expected the second stage once more, got %s left", clock.GetRemaining(WHITE))
+	}
+}
+
+func TestClockFlag(t *testing.T) {
+	control, err := ParseTimeControl("1+2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := playClock(control, 30*time.Second, 0, 31*time.Second)
+	if clock.IsFlagged(WHITE) {
+		t.Errorf("increment did not save white, %s left", clock.GetRemaining(WHITE))
+	}
+	clock = playClock(control, 30*time.Second, 0, 30*time.Second, 70*time.Second)
+	if !clock.IsFlagged(BLACK) {
+		t.Errorf("black not flagged with %s left", clock.GetRemaining(BLACK))
+	}
+}
+
+func TestFormatClock(t *testing.T) {
+	for remaining, expected := range map[time.Duration]string{
+		4*time.Minute + 57*time.Second:       "4:57",
+		90 * time.Minute:                     "1:30:00",
+		9*time.Second + 540*time.Millisecond: "0:09.5",
+		-time.Second:                         "0:00.0",
+	} {
+		if FormatClock(remaining) != expected {
+			t.Errorf("expected %s to show as %s, got %s", remaining, expected, FormatClock(remaining))
+		}
+	}
+}
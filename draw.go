@@ -0,0 +1,138 @@
+package main
+
+// DrawRule is a rule that ends a game in a draw, either when a player claims
+// it or automatically
+type DrawRule int
+
+const (
+	// NODRAW is when no rule ends the game in a draw
+	NODRAW DrawRule = iota
+	// THREEFOLD is when the same position appeared three times, on a claim
+	THREEFOLD
+	// FIFTYMOVES is when both teams made 50 moves without a capture or a pawn
+	// move, on a claim
+	FIFTYMOVES
+	// FIVEFOLD is when the same position appeared five times
+	FIVEFOLD
+	// SEVENTYFIVEMOVES is when both teams made 75 moves without a capture or a
+	// pawn move
+	SEVENTYFIVEMOVES
+	// INSUFFICIENTMATERIAL is when neither team has the pieces to checkmate
+	INSUFFICIENTMATERIAL
+	// AGREEMENT is when a team accepts the draw the enemy offered
+	AGREEMENT
+)
+
+// lightSquares are the light squares of the board, a8 being one of them
+const lightSquares Bitboard = 0xAA55AA55AA55AA55
+
+// GetDrawRuleName returns the name of a draw rule, e.g. "threefold repetition"
+func GetDrawRuleName(rule DrawRule) string {
+	drawRuleNames := map[DrawRule]string{
+		THREEFOLD:            "threefold repetition",
+		FIFTYMOVES:           "the 50-move rule",
+		FIVEFOLD:             "fivefold repetition",
+		SEVENTYFIVEMOVES:     "the 75-move rule",
+		INSUFFICIENTMATERIAL: "insufficient material",
+		AGREEMENT:            "agreement",
+	}
+	return drawRuleNames[rule]
+}
+
+// GetRepetitions returns how many times the current position has appeared in
+// the game, counting this one
+func (g Game) GetRepetitions() int {
+	hash := g.GetHash()
+	repetitions := 1
+	for _, previous := range g.history {
+		if previous == hash {
+			repetitions++
+		}
+	}
+	return repetitions
+}
+
+// GetClaimableDraw returns the rule a player can claim a draw by, NODRAW if
+// none applies
+func (g Game) GetClaimableDraw() DrawRule {
+	if g.GetRepetitions() >= 3 {
+		return THREEFOLD
+	}
+	if g.halfmoves >= 100 {
+		return FIFTYMOVES
+	}
+	return NODRAW
+}
+
+// GetAutomaticDraw returns the rule that ends the game in a draw without a
+// claim, NODRAW if none applies
+// A checkmate on the last move still wins, so GetResult looks at it first.
+func (g Game) GetAutomaticDraw() DrawRule {
+	if g.GetRepetitions() >= 5 {
+		return FIVEFOLD
+	}
+	if g.halfmoves >= 150 {
+		return SEVENTYFIVEMOVES
+	}
+	if g.position.IsInsufficientMaterial() {
+		return INSUFFICIENTMATERIAL
+	}
+	return NODRAW
+}
+
+// IsInsufficientMaterial returns whether neither team has the pieces left to
+// checkmate, whatever the moves
+// That is the case of a King against a King, alone or with a single Bishop or
+// Knight on one side, or with one Bishop each on squares of the same color.
+func (p Position) IsInsufficientMaterial() bool {
+	for _, team := range []Team{WHITE, BLACK} {
+		if p.pieces[team][PAWN]|p.pieces[team][ROOK]|p.pieces[team][QUEEN] != 0 {
+			return false
+		}
+	}
+	knights := p.pieces[WHITE][KNIGHT] | p.pieces[BLACK][KNIGHT]
+	bishops := p.pieces[WHITE][BISHOP] | p.pieces[BLACK][BISHOP]
+	if (knights | bishops).Count() <= 1 {
+		return true
+	}
+	if knights != 0 || p.pieces[WHITE][BISHOP].Count() != 1 || p.pieces[BLACK][BISHOP].Count() != 1 {
+		return false
+	}
+	return bishops&lightSquares == 0 || bishops&^lightSquares == 0
+}
+
+// HasMatingMaterial returns whether given team has the pieces left to
+// checkmate the enemy King by some series of moves, however unlikely
+// A single Knight, or Bishops all on squares of one color, can only checkmate
+// a King that its own pieces box in, which Bishops on that same color cannot.
+func (p Position) HasMatingMaterial(team Team) bool {
+	enemy := WHITE
+	if team == WHITE {
+		enemy = BLACK
+	}
+	pieces := &p.pieces[team]
+	if pieces[PAWN]|pieces[ROOK]|pieces[QUEEN] != 0 {
+		return true
+	}
+	knights := pieces[KNIGHT]
+	bishops := pieces[BISHOP]
+	if knights == 0 && bishops == 0 {
+		return false
+	}
+	if knights.Count() >= 2 || (knights != 0 && bishops != 0) {
+		return true
+	}
+	if bishops&lightSquares != 0 && bishops&^lightSquares != 0 {
+		return true
+	}
+
+	blockers := p.teams[enemy] &^ p.pieces[enemy][KING]
+	if bishops != 0 {
+		color := lightSquares
+		if bishops&lightSquares == 0 {
+			color = ^lightSquares
+		}
+		blockers &^= p.pieces[enemy][BISHOP] & color
+	}
+	return blockers != 0
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+)
+
+// shuffle is white and black moving their Knights out and back again
+var shuffle = []string{"Nf3", "Nf6", "Ng1", "Ng8"}
+
+func TestThreefoldRepetition(t *testing.T) {
+	game := playMoves(t, NewGame(), shuffle...)
+	if game.GetRepetitions() != 2 || game.GetClaimableDraw() != NODRAW {
+		t.Errorf("draw claimable after the initial position appeared %d times", game.GetRepetitions())
+	}
+	game = playMoves(t, game, shuffle...)
+	if game.GetClaimableDraw() != THREEFOLD {
+		t.Errorf("no threefold repetition after the initial position appeared %d times", game.GetRepetitions())
+	}
+	if game.GetResult() != UNFINISHED {
+		t.Error("threefold repetition ended the game without a claim")
+	}
+}
+
+func TestFivefoldRepetition(t *testing.T) {
+	game := playMoves(t, NewGame(), append(append(append(shuffle, shuffle...), shuffle...), shuffle[:3]...)...)
+	_, _, messages, isEndgame := game.NewMove("Ng8")
+	if !isEndgame || messages[len(messages)-1] != "DRAW: by fivefold repetition" {
+		t.Errorf("fivefold repetition did not end the game: %v", messages)
+	}
+	game = playMoves(t, game, "Ng8")
+	if game.GetAutomaticDraw() != FIVEFOLD || game.GetResult() != DRAW {
+		t.Error("fivefold repetition is not a draw")
+	}
+}
+
+func TestRepetitionAfterPawnMove(t *testing.T) {
+	game := playMoves(t, NewGame(), "Nf3", "Nf6", "Ng1", "Ng8", "e4", "e5")
+	game = playMoves(t, game, "Nf3", "Nf6", "Ng1", "Ng8")
+	if game.GetRepetitions() != 2 {
+		t.Errorf("expected position after the pawn moves twice, got %d times", game.GetRepetitions())
+	}
+}
+
+func TestMoveRules(t *testing.T) {
+	game, err := NewGameFromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 99 80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.GetClaimableDraw() != NODRAW {
+		t.Error("50-move rule applies after 99 halfmoves")
+	}
+	game = playMoves(t, game, "Ra2")
+	if game.GetClaimableDraw() != FIFTYMOVES || game.GetResult() != UNFINISHED {
+		t.Error("50-move rule does not apply after 100 halfmoves, or ends the game without a claim")
+	}
+
+	game, err = NewGameFromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 149 105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	game = playMoves(t, game, "Ra2")
+	if game.GetAutomaticDraw() != SEVENTYFIVEMOVES || game.GetResult() != DRAW {
+		t.Error("75-move rule does not end the game after 150 halfmoves")
+	}
+}
+
+func TestSeventyFiveMovesCheckmate(t *testing.T) {
+	game, err := NewGameFromFEN("6k1/5ppp/8/8/8/8/8/R3K3 w - - 149 105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	game = playMoves(t, game, "Ra8#")
+	if game.GetResult() != WHITEWINS {
+		t.Errorf("checkmate on the 150th halfmove is a %s", GetResultName(game.GetResult()))
+	}
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	for fen, expected := range map[string]bool{
+		"4k3/8/8/8/8/8/8/4K3 w - - 0 1":     true,
+		"4k3/8/8/8/8/8/8/2B1K3 w - - 0 1":   true,
+		"4k3/8/8/8/8/8/8/1N2K3 b - - 0 1":   true,
+		"2b1k3/8/8/8/8/8/8/4KB2 w - - 0 1":  true,
+		"1b2k3/8/8/8/8/8/8/4KB2 w - - 0 1":  false,
+		"4k3/8/8/8/8/8/8/1NB1K3 w - - 0 1":  false,
+		"1n2k3/8/8/8/8/8/8/1N2K3 w - - 0 1": false,
+		"2b1k3/8/8/8/8/8/8/1N2K3 w - - 0 1": false,
+		"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1":   false,
+		"4k3/8/8/8/8/8/8/R3K3 w - - 0 1":    false,
+	} {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game.position.IsInsufficientMaterial() != expected {
+			t.Errorf("%s: expected insufficient material to be %t", fen, expected)
+		}
+	}
+}
+
+func TestInsufficientMaterialEndsGame(t *testing.T) {
+	game, err := NewGameFromFEN("4k3/8/8/8/8/8/3r4/2N1K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, isValid, messages, isEndgame := game.NewMove("Kxd2")
+	if !isValid || !isEndgame || messages[len(messages)-1] != "DRAW: by insufficient material" {
+		t.Errorf("capturing the last Rook did not end the game: %v", messages)
+	}
+	_, _, messages, isEndgame = game.NewMove("Nb3")
+	if isEndgame {
+		t.Errorf("moving with the Rook left on the board ended the game: %v", messages)
+	}
+}
+
+func TestMatingMaterial(t *testing.T) {
+	for fen, expected := range map[string]bool{
+		"3qk3/8/8/8/8/8/8/4K3 w - - 0 1":    false,
+		"4k3/8/8/8/8/8/8/1N2K3 w - - 0 1":   false,
+		"4k3/4p3/8/8/8/8/8/1N2K3 w - - 0 1": true,
+		"2b1k3/8/8/8/8/8/8/4KB2 w - - 0 1":  false,
+		"1b2k3/8/8/8/8/8/8/4KB2 w - - 0 1":  true,
+		"4k3/8/8/8/8/8/8/1NN1K3 w - - 0 1":  true,
+		"4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1":  true,
+		"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1":   true,
+	} {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game.position.HasMatingMaterial(WHITE) != expected {
+			t.Errorf("%s: expected white mating material to be %t", fen, expected)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// INFINITY is a score beyond any a search returns, to open its window
+	INFINITY = 32000
+	// MATE is the score of checkmating right away, a mate n plies later
+	// scoring n less, so that the engine goes for the quickest one
+	MATE = 30000
+	// maxPly is the deepest a search goes, in plies from the root
+	maxPly = 64
+	// defaultDepth is how deep an engine searches with no limit given
+	defaultDepth = 4
+	// defaultHashSize is the size of the transposition table in megabytes
+	defaultHashSize = 16
+)
+
+// Engine is a computer player, searching the moves of the team to play with
+// negamax alpha-beta
+// It deepens its search one ply at a time until it reaches its depth or runs
+// out of time, and follows captures past the depth until the position is quiet.
+type Engine struct {
+	// depth is how many plies the search reaches, 0 for no limit
+	depth int
+	// moveTime is how long a search may take, 0 for no limit
+	moveTime time.Duration
+	now      func() time.Time
+	tt       *TranspositionTable
+	// heuristics are the ways moves are ordered, all of them by default
+	heuristics Heuristic
+	ordering   Ordering
+
+	// line is the moves played from the root to the position searched, by ply
+	line      [maxPly + 1]Move
+	nodes     int
+	deadline  time.Time
+	completed int
+	isStopped bool
+}
+
+// SearchResult is the outcome of a search, the best move found and its score
+// from the view of the team to play
+type SearchResult struct {
+	move  Move
+	score int
+	depth int
+	nodes int
+}
+
+// NewEngine returns an engine searching to given depth and for at most given
+// time per move, with a transposition table of given size in megabytes,
+// reading time from now, e.g. time.Now
+// Without either limit, it searches to defaultDepth.
+func NewEngine(depth int, moveTime time.Duration, hashSize int, now func() time.Time) *Engine {
+	if depth <= 0 && moveTime <= 0 {
+		depth = defaultDepth
+	}
+	if hashSize <= 0 {
+		hashSize = defaultHashSize
+	}
+	return &Engine{
+		depth:      depth,
+		moveTime:   moveTime,
+		now:        now,
+		tt:         NewTranspositionTable(hashSize),
+		heuristics: allHeuristics,
+	}
+}
+
+// NewGame forgets what the engine found in earlier games
+func (e *Engine) NewGame() {
+	e.tt.Clear()
+	e.ordering = Ordering{}
+}
+
+// Search returns the best move of the team to play, searching for at most
+// timeLimit, 0 for no limit
+// The result of the last depth searched to the end stands; a depth cut short
+// by time is dropped, but the first depth always completes.
+func (e *Engine) Search(g Game, timeLimit time.Duration) SearchResult {
+	e.nodes = 0
+	e.completed = 0
+	e.isStopped = false
+	e.deadline = time.Time{}
+	e.tt.NewSearch()
+	e.ordering.NewSearch()
+	if timeLimit > 0 {
+		e.deadline = e.now().Add(timeLimit)
+	}
+
+	result := SearchResult{}
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		return result
+	}
+	hashMove := Move{}
+	if entry, isFound := e.tt.Probe(g.GetHash()); isFound {
+		hashMove = entry.move
+	}
+	ranks := e.RankMoves(&g, moves, 0, hashMove, Move{})
+	for i := range moves {
+		PickMove(moves, ranks, i)
+	}
+	result.move = moves[0]
+	for depth := 1; depth <= maxPly && (e.depth == 0 || depth <= e.depth); depth++ {
+		alpha := -INFINITY
+		bestIndex := 0
+		for i, m := range moves {
+			e.line[0] = m
+			u := g.Make(m)
+			score := -e.Negamax(&g, depth-1, 1, -INFINITY, -alpha)
+			g.Unmake(u)
+			if e.isStopped {
+				break
+			}
+			if score > alpha {
+				alpha = score
+				bestIndex = i
+			}
+		}
+		if e.isStopped {
+			break
+		}
+
+		// the best move goes first, to be searched first one ply deeper
+		best := moves[bestIndex]
+		copy(moves[1:bestIndex+1], moves[:bestIndex])
+		moves[0] = best
+		e.completed = depth
+		e.tt.Store(g.GetHash(), depth, 0, alpha, EXACT, best)
+		result = SearchResult{move: best, score: alpha, depth: depth, nodes: e.nodes}
+
+		// no deeper search finds a quicker mate
+		if IsMateScore(alpha) {
+			break
+		}
+	}
+	result.nodes = e.nodes
+	return result
+}
+
+// Negamax returns the score of the game from the view of the team to play,
+// searching depth plies ahead, ply plies away from the root
+// Scores outside the alpha-beta window are cut off, as the enemy would never
+// allow them, or the team to play has a better move elsewhere.
+func (e *Engine) Negamax(g *Game, depth int, ply int, alpha int, beta int) int {
+	if e.IsTimeUp() {
+		return 0
+	}
+	if g.GetRepetitions() >= 2 || g.halfmoves >= 100 || g.position.IsInsufficientMaterial() {
+		return 0
+	}
+
+	// a check is followed one ply further, not to miss what it leads to
+	isChecked := g.position.IsChecked(g.turn)
+	if isChecked && ply < maxPly {
+		depth++
+	}
+	if depth <= 0 || ply >= maxPly {
+		return e.Quiesce(g, ply, alpha, beta)
+	}
+	e.nodes++
+
+	// a position searched before as deep needs no search, if its score is
+	// exact or already out of the window
+	hash := g.GetHash()
+	entry, isFound := e.tt.Probe(hash)
+	if isFound && int(entry.depth) >= depth {
+		score := FromTTScore(int(entry.score), ply)
+		if entry.bound == EXACT || (entry.bound == LOWERBOUND && score >= beta) || (entry.bound == UPPERBOUND && score <= alpha) {
+			return score
+		}
+	}
+
+	moves := g.LegalMoves()
+	if len(moves) == 0 {
+		if isChecked {
+			return -MATE + ply
+		}
+		return 0
+	}
+
+	// the best move found before is likely the best again, so it goes first
+	hashMove := Move{}
+	if isFound {
+		hashMove = entry.move
+	}
+	previous := e.line[ply-1]
+	ranks := e.RankMoves(g, moves, ply, hashMove, previous)
+
+	bound := UPPERBOUND
+	best := moves[0]
+	for i := range moves {
+		PickMove(moves, ranks, i)
+		m := moves[i]
+		e.line[ply] = m
+		u := g.Make(m)
+		score := -e.Negamax(g, depth-1, ply+1, -beta, -alpha)
+		g.Unmake(u)
+		if e.isStopped {
+			return 0
+		}
+		if score >= beta {
+			if m.IsQuiet() {
+				e.UpdateOrdering(m, depth, ply, previous)
+			}
+			e.tt.Store(hash, depth, ply, beta, LOWERBOUND, m)
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+			bound = EXACT
+			best = m
+		}
+	}
+	e.tt.Store(hash, depth, ply, alpha, bound, best)
+	return alpha
+}
+
+// Quiesce returns the score of the game from the view of the team to play,
+// once captures and promotions have played out
+// The team to play may stand pat instead of capturing, so the score is at
+// least that of the game as it is.
+func (e *Engine) Quiesce(g *Game, ply int, alpha int, beta int) int {
+	if e.IsTimeUp() {
+		return 0
+	}
+	e.nodes++
+
+	standPat := g.Evaluate()
+	if standPat >= beta || ply >= maxPly {
+		return standPat
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+	// captures are always ordered by MVV-LVA here, as trying them in any
+	// order makes quiescence search far too many of them
+	moves := g.LegalCaptures()
+	ranks := make([]int, len(moves))
+	for i, m := range moves {
+		ranks[i] = GetMVVLVA(g.position, m)
+	}
+	for i := range moves {
+		PickMove(moves, ranks, i)
+		m := moves[i]
+
+		// a capture that loses material once the exchange plays out cannot
+		// raise the score above standing pat
+		if g.position.SEE(m) < 0 {
+			continue
+		}
+		u := g.Make(m)
+		score := -e.Quiesce(g, ply+1, -beta, -alpha)
+		g.Unmake(u)
+		if e.isStopped {
+			return 0
+		}
+		if score >= beta {
+			return beta
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return alpha
+}
+
+// IsTimeUp returns whether the search has to stop, its time being over
+// The clock is read once every 1024 nodes, and never before the first depth
+// completes, so that there is always a move to play.
+func (e *Engine) IsTimeUp() bool {
+	if e.isStopped {
+		return true
+	}
+	if e.deadline.IsZero() || e.completed == 0 || e.nodes%1024 != 0 {
+		return false
+	}
+	e.isStopped = e.now().After(e.deadline)
+	return e.isStopped
+}
+
+// IsMateScore returns whether a score is that of a forced checkmate
+func IsMateScore(score int) bool {
+	return score > MATE-maxPly || score < -MATE+maxPly
+}
+
+// FormatScore returns a score the way engines show it, in pawns, e.g. "+0.35",
+// or in moves to checkmate, e.g. "M3", or "-M2" when getting checkmated
+func FormatScore(score int) string {
+	if score > MATE-maxPly {
+		return fmt.Sprintf("M%d", (MATE-score+1)/2)
+	}
+	if score < -MATE+maxPly {
+		return fmt.Sprintf("-M%d", (MATE+score+1)/2)
+	}
+	return fmt.Sprintf("%+.2f", float64(score)/100)
+}
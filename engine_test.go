@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// searchFEN searches the game of given FEN to given depth
+func searchFEN(t *testing.T, fen string, depth int) (Game, SearchResult) {
+	game, err := NewGameFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game, NewEngine(depth, 0, 1, time.Now).Search(game, 0)
+}
+
+func TestEngineMates(t *testing.T) {
+	// back rank mate
+	game, search := searchFEN(t, "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 4)
+	if san := game.GetSAN(search.move); san != "Ra8#" || search.score != MATE-1 {
+		t.Errorf("expected Ra8# to mate in 1, got %s scoring %s", san, FormatScore(search.score))
+	}
+
+	// the Rooks take turns to push the King to the edge
+	_, search = searchFEN(t, "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1", 4)
+	if search.score != MATE-3 {
+		t.Errorf("expected a mate in 2, got %s", FormatScore(search.score))
+	}
+
+	// the losing side sees it coming
+	_, search = searchFEN(t, "7k/R7/8/8/8/8/8/1R4K1 b - - 0 1", 4)
+	if search.score != -MATE+2 {
+		t.Errorf("expected to get mated in 1, got %s", FormatScore(search.score))
+	}
+}
+
+func TestEngineCaptures(t *testing.T) {
+	// the Queen hangs, but the pawn on e5 is defended
+	game, search := searchFEN(t, "4k3/8/3p4/4p3/3Q4/8/8/4K3 w - - 0 1", 3)
+	if san := game.GetSAN(search.move); strings.HasPrefix(san, "Qxe5") {
+		t.Errorf("expected the Queen to stay away from e5, got %s", san)
+	}
+
+	// quiescence sees the Rook recapturing beyond the depth
+	game, search = searchFEN(t, "3rk3/8/8/3p4/8/8/8/3QK3 w - - 0 1", 1)
+	if san := game.GetSAN(search.move); san == "Qxd5" {
+		t.Errorf("expected the Queen not to take the defended pawn, got %s", san)
+	}
+}
+
+func TestEngineTimeLimit(t *testing.T) {
+	game := NewGame()
+	source := &fakeTime{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	engine := NewEngine(0, 0, 1, func() time.Time {
+		// every read of the clock takes a second
+		source.now = source.now.Add(time.Second)
+		return source.now
+	})
+	search := engine.Search(game, 3*time.Second)
+	if search.depth == 0 || search.depth >= maxPly {
+		t.Errorf("expected the search to stop on time, reached depth %d", search.depth)
+	}
+	if m, isValid, _, _ := game.NewMove(search.move.AsCommand()); !isValid || m != search.move {
+		t.Errorf("expected a legal move, got %s", search.move.AsCommand())
+	}
+}
+
+func TestFormatScore(t *testing.T) {
+	for score, expected := range map[int]string{
+		35:        "+0.35",
+		-120:      "-1.20",
+		MATE - 1:  "M1",
+		MATE - 3:  "M2",
+		-MATE + 2: "-M1",
+	} {
+		if FormatScore(score) != expected {
+			t.Errorf("expected %d to show as %s, got %s", score, expected, FormatScore(score))
+		}
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Term is a part of the static evaluation of a position
+type Term int
+
+const (
+	// MATERIAL is the value of the pieces on the board
+	MATERIAL Term = iota
+	// PIECESQUARES is how good the squares the pieces stand on are
+	PIECESQUARES
+	// MOBILITY is how many squares the pieces can go to
+	MOBILITY
+	// KINGSAFETY is how well the King is sheltered by pawns and away from
+	// enemy attacks
+	KINGSAFETY
+	// PASSEDPAWNS is the pawns no enemy pawn can stop on their way to promote
+	PASSEDPAWNS
+	// DOUBLEDPAWNS is the pawns standing on the column of another one
+	DOUBLEDPAWNS
+	// ISOLATEDPAWNS is the pawns with no pawn of their team on the columns
+	// beside them
+	ISOLATEDPAWNS
+	// BISHOPPAIR is having both Bishops
+	BISHOPPAIR
+	// ROOKFILES is the Rooks on columns free of pawns
+	ROOKFILES
+)
+
+// terms are all the terms of the evaluation, in the order they are shown
+var terms = []Term{MATERIAL, PIECESQUARES, MOBILITY, KINGSAFETY, PASSEDPAWNS, DOUBLEDPAWNS, ISOLATEDPAWNS, BISHOPPAIR, ROOKFILES}
+
+// GetTermName returns the name of an evaluation term, e.g. "passed pawns"
+func GetTermName(term Term) string {
+	termNames := map[Term]string{
+		MATERIAL:      "material",
+		PIECESQUARES:  "piece-square tables",
+		MOBILITY:      "mobility",
+		KINGSAFETY:    "king safety",
+		PASSEDPAWNS:   "passed pawns",
+		DOUBLEDPAWNS:  "doubled pawns",
+		ISOLATEDPAWNS: "isolated pawns",
+		BISHOPPAIR:    "bishop pair",
+		ROOKFILES:     "rooks on open files",
+	}
+	return termNames[term]
+}
+
+// Score is a score in centipawns both in the middlegame and in the endgame,
+// blended by how much material is left
+type Score struct {
+	mg int
+	eg int
+}
+
+// Add adds a score to this one
+func (s *Score) Add(other Score) {
+	s.mg += other.mg
+	s.eg += other.eg
+}
+
+// Scale returns the score multiplied by n, e.g. by a number of pawns
+func (s Score) Scale(n int) Score {
+	return Score{mg: s.mg * n, eg: s.eg * n}
+}
+
+const (
+	// maxPhase is the phase of a game with all its pieces, the middlegame
+	// ending as the phase goes down to 0 with only Kings and pawns left
+	maxPhase = 24
+)
+
+// phaseWeights are how much each piece counts towards the phase, by Piece
+var phaseWeights = [6]int{
+	PAWN:   0,
+	ROOK:   2,
+	KNIGHT: 1,
+	BISHOP: 1,
+	QUEEN:  4,
+	KING:   0,
+}
+
+// materialScores are the values of the pieces, by Piece
+var materialScores = [6]Score{
+	PAWN:   {mg: 82, eg: 94},
+	ROOK:   {mg: 477, eg: 512},
+	KNIGHT: {mg: 337, eg: 281},
+	BISHOP: {mg: 365, eg: 297},
+	QUEEN:  {mg: 1025, eg: 936},
+	KING:   {},
+}
+
+// Piece-square tables give a bonus per square, from the view of white, with
+// a8 first, so square by square as the Position stores them. Black reads them
+// upside down.
+var (
+	pawnTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	pawnEndgameTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		80, 80, 80, 80, 80, 80, 80, 80,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		30, 30, 30, 30, 30, 30, 30, 30,
+		20, 20, 20, 20, 20, 20, 20, 20,
+		10, 10, 10, 10, 10, 10, 10, 10,
+		10, 10, 10, 10, 10, 10, 10, 10,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	knightTable = [64]int{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	}
+	bishopTable = [64]int{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	}
+	rookTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	}
+	queenTable = [64]int{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	}
+	kingTable = [64]int{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	}
+	kingEndgameTable = [64]int{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	}
+)
+
+// pieceSquareScores are the piece-square bonuses of white, by Piece and square
+var pieceSquareScores [6][64]Score
+
+// mobilityScores are the bonuses per square a piece can go to beyond
+// mobilityBase, by Piece
+var mobilityScores = [6]Score{
+	KNIGHT: {mg: 4, eg: 4},
+	BISHOP: {mg: 5, eg: 5},
+	ROOK:   {mg: 2, eg: 4},
+	QUEEN:  {mg: 1, eg: 2},
+}
+
+// mobilityBase is the number of squares a piece usually goes to, by Piece
+var mobilityBase = [6]int{
+	KNIGHT: 4,
+	BISHOP: 7,
+	ROOK:   7,
+	QUEEN:  14,
+}
+
+// kingAttackWeights are how dangerous a piece attacking squares around the
+// enemy King is, by Piece
+var kingAttackWeights = [6]int{
+	KNIGHT: 2,
+	BISHOP: 2,
+	ROOK:   3,
+	QUEEN:  5,
+}
+
+var (
+	// shieldScore is the bonus per pawn in front of its King
+	shieldScore = Score{mg: 10}
+	// passedScores are the bonuses of a passed pawn, by rows advanced
+	passedScores = [8]Score{{}, {5, 10}, {10, 20}, {15, 35}, {25, 60}, {40, 100}, {60, 150}, {}}
+	// doubledScore is the penalty per pawn doubled on a column
+	doubledScore = Score{mg: -10, eg: -20}
+	// isolatedScore is the penalty per isolated pawn
+	isolatedScore = Score{mg: -10, eg: -15}
+	// bishopPairScore is the bonus of having both Bishops
+	bishopPairScore = Score{mg: 30, eg: 50}
+	// openFileScore is the bonus of a Rook on a column with no pawns
+	openFileScore = Score{mg: 25, eg: 10}
+	// halfOpenFileScore is the bonus of a Rook on a column with only enemy
+	// pawns
+	halfOpenFileScore = Score{mg: 12, eg: 5}
+)
+
+// columnMasks are the squares of each column
+var columnMasks [8]Bitboard
+
+// adjacentColumnMasks are the squares of the columns beside each column
+var adjacentColumnMasks [8]Bitboard
+
+// passedMasks are the squares ahead of a pawn of each team on each square,
+// on its column and the ones beside it, where no enemy pawn may stand for it
+// to be passed
+var passedMasks [2][64]Bitboard
+
+// shieldMasks are the squares in front of a King of each team on each
+// square, where its pawns shelter it
+var shieldMasks [2][64]Bitboard
+
+func init() {
+	tables := [6][2]*[64]int{
+		PAWN:   {&pawnTable, &pawnEndgameTable},
+		ROOK:   {&rookTable, &rookTable},
+		KNIGHT: {&knightTable, &knightTable},
+		BISHOP: {&bishopTable, &bishopTable},
+		QUEEN:  {&queenTable, &queenTable},
+		KING:   {&kingTable, &kingEndgameTable},
+	}
+	for piece, table := range tables {
+		for square := 0; square < 64; square++ {
+			pieceSquareScores[piece][square] = Score{mg: table[0][square], eg: table[1][square]}
+		}
+	}
+
+	for col := 0; col < 8; col++ {
+		for row := 0; row < 8; row++ {
+			columnMasks[col] |= NewBitboard(Location{row: row, col: col})
+		}
+	}
+	for col := 0; col < 8; col++ {
+		if col > 0 {
+			adjacentColumnMasks[col] |= columnMasks[col-1]
+		}
+		if col < 7 {
+			adjacentColumnMasks[col] |= columnMasks[col+1]
+		}
+	}
+
+	for _, team := range []Team{WHITE, BLACK} {
+		forward := GetPawnDirection(team)
+		for square := 0; square < 64; square++ {
+			row, col := square/8, square%8
+			for r := row + forward; r >= 0 && r < 8; r += forward {
+				for c := col - 1; c <= col+1; c++ {
+					if IsLocationValid(r, c) {
+						passedMasks[team][square] |= NewBitboard(Location{row: r, col: c})
+					}
+				}
+			}
+			for r := row + forward; r != row+3*forward && r >= 0 && r < 8; r += forward {
+				for c := col - 1; c <= col+1; c++ {
+					if IsLocationValid(r, c) {
+						shieldMasks[team][square] |= NewBitboard(Location{row: r, col: c})
+					}
+				}
+			}
+		}
+	}
+}
+
+// Evaluation is the static evaluation of a position, broken down per term and
+// team, along with the phase its scores are blended by
+type Evaluation struct {
+	scores [ROOKFILES + 1][2]Score
+	phase  int
+}
+
+// Evaluate returns the static evaluation of the game in centipawns, from the
+// view of the team to play
+func (g Game) Evaluate() int {
+	score := g.position.GetEvaluation().GetTotal()
+	if g.turn == BLACK {
+		return -score
+	}
+	return score
+}
+
+// GetEvaluation returns the static evaluation of the position, from the view
+// of white
+func (p Position) GetEvaluation() Evaluation {
+	e := Evaluation{}
+	occupied := p.GetOccupied()
+	pawns := p.pieces[WHITE][PAWN] | p.pieces[BLACK][PAWN]
+	for _, team := range []Team{WHITE, BLACK} {
+		enemy := WHITE
+		if team == WHITE {
+			enemy = BLACK
+		}
+		scores := &e.scores
+		ownPawns := p.pieces[team][PAWN]
+		enemyPawns := p.pieces[enemy][PAWN]
+
+		// squares attacked by enemy pawns are no safe place to go
+		unsafe := Bitboard(0)
+		for attackers := enemyPawns; attackers != 0; {
+			unsafe |= pawnAttacks[enemy][attackers.PopFirst()]
+		}
+		kingZone := Bitboard(0)
+		if king := p.pieces[enemy][KING]; king != 0 {
+			kingZone = kingAttacks[king.First()] | king
+		}
+		kingAttackUnits := 0
+
+		for piece := PAWN; piece <= KING; piece++ {
+			for squares := p.pieces[team][piece]; squares != 0; {
+				square := squares.PopFirst()
+				e.phase += phaseWeights[piece]
+				scores[MATERIAL][team].Add(materialScores[piece])
+				tableSquare := square
+				if team == BLACK {
+					tableSquare ^= 56
+				}
+				scores[PIECESQUARES][team].Add(pieceSquareScores[piece][tableSquare])
+
+				if piece == PAWN || piece == KING {
+					continue
+				}
+				attacks := GetPieceAttacks(piece, team, square, occupied)
+				moves := (attacks &^ p.teams[team] &^ unsafe).Count()
+				scores[MOBILITY][team].Add(mobilityScores[piece].Scale(moves - mobilityBase[piece]))
+				kingAttackUnits += kingAttackWeights[piece] * (attacks & kingZone).Count()
+			}
+		}
+
+		// the enemy King is the less safe the more its surroundings are attacked
+		scores[KINGSAFETY][enemy].Add(Score{mg: -kingAttackUnits * kingAttackUnits / 8})
+		if king := p.pieces[team][KING]; king != 0 {
+			shield := (shieldMasks[team][king.First()] & ownPawns).Count()
+			if shield > 3 {
+				shield = 3
+			}
+			scores[KINGSAFETY][team].Add(shieldScore.Scale(shield))
+		}
+
+		for squares := ownPawns; squares != 0; {
+			square := squares.PopFirst()
+			col := square % 8
+			if passedMasks[team][square]&enemyPawns == 0 {
+				advanced := square / 8
+				if team == WHITE {
+					advanced = 7 - advanced
+				}
+				scores[PASSEDPAWNS][team].Add(passedScores[advanced])
+			}
+			if adjacentColumnMasks[col]&ownPawns == 0 {
+				scores[ISOLATEDPAWNS][team].Add(isolatedScore)
+			}
+		}
+		for col := 0; col < 8; col++ {
+			if count := (columnMasks[col] & ownPawns).Count(); count > 1 {
+				scores[DOUBLEDPAWNS][team].Add(doubledScore.Scale(count - 1))
+			}
+		}
+
+		if p.pieces[team][BISHOP].Count() >= 2 {
+			scores[BISHOPPAIR][team].Add(bishopPairScore)
+		}
+		for rooks := p.pieces[team][ROOK]; rooks != 0; {
+			column := columnMasks[rooks.PopFirst()%8]
+			if column&pawns == 0 {
+				scores[ROOKFILES][team].Add(openFileScore)
+			} else if column&ownPawns == 0 {
+				scores[ROOKFILES][team].Add(halfOpenFileScore)
+			}
+		}
+	}
+	if e.phase > maxPhase {
+		e.phase = maxPhase
+	}
+	return e
+}
+
+// Taper blends the middlegame and endgame parts of a score by the phase
+func (e Evaluation) Taper(s Score) int {
+	return (s.mg*e.phase + s.eg*(maxPhase-e.phase)) / maxPhase
+}
+
+// GetTermScore returns the score of a term for given team alone
+func (e Evaluation) GetTermScore(term Term, team Team) int {
+	return e.Taper(e.scores[term][team])
+}
+
+// GetTerm returns the score of a term from the view of white, that is what
+// it gives white minus what it gives black
+func (e Evaluation) GetTerm(term Term) int {
+	return e.GetTermScore(term, WHITE) - e.GetTermScore(term, BLACK)
+}
+
+// GetTotal returns the score of the position from the view of white
+// The terms are summed before tapering, so the total may differ by a
+// centipawn or so from the sum of the terms as shown.
+func (e Evaluation) GetTotal() int {
+	total := Score{}
+	for _, term := range terms {
+		total.Add(e.scores[term][WHITE])
+		total.Add(e.scores[term][BLACK].Scale(-1))
+	}
+	return e.Taper(total)
+}
+
+// PrintEvaluation writes the evaluation of the game broken down per term, in
+// centipawns from the view of each team, and its total from the view of the
+// team to play
+func PrintEvaluation(game Game, output io.Writer) {
+	e := game.position.GetEvaluation()
+	fmt.Fprintf(output, "%-20s %7s %7s %7s\n", "term", "white", "black", "total")
+	for _, term := range terms {
+		fmt.Fprintf(output, "%-20s %7d %7d %+7d\n", GetTermName(term), e.GetTermScore(term, WHITE), e.GetTermScore(term, BLACK), e.GetTerm(term))
+	}
+	fmt.Fprintf(output, "%-20s %7d\n", "phase", e.phase)
+	fmt.Fprintf(output, "%s plays, scoring %+d\n", GetTeamName(game.turn, LOWER), game.Evaluate())
+}
+
+// RunEval runs the eval command, e.g. "chess eval [fen]"
+// It prints the evaluation of the position broken down per term. The position
+// defaults to the initial one, and the FEN may be given unquoted.
+func RunEval(args []string, output io.Writer) error {
+	game := NewGame()
+	if len(args) > 0 {
+		var err error
+		game, err = NewGameFromFEN(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+	}
+	if game.position.pieces[WHITE][KING] == 0 || game.position.pieces[BLACK][KING] == 0 {
+		return errors.New("invalid position to evaluate, each team needs a King")
+	}
+	PrintEvaluation(game, output)
+	return nil
+}
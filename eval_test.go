@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// evaluateFEN returns the evaluation of the position of given FEN
+func evaluateFEN(t *testing.T, fen string) (Game, Evaluation) {
+	game, err := NewGameFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game, game.position.GetEvaluation()
+}
+
+func TestEvaluateSymmetry(t *testing.T) {
+	if score := NewGame().Evaluate(); score != 0 {
+		t.Errorf("expected the initial position to score 0, got %d", score)
+	}
+
+	// the same position with colors swapped and the board upside down
+	for fen, mirrored := range map[string]string{
+		"r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4": "rnbqk2r/pppp1ppp/5n2/2b1p3/4P3/2N2N2/PPPP1PPP/R1BQKB1R b KQkq - 4 4",
+		"8/5pk1/6p1/3P4/8/6P1/5PK1/2BR4 w - - 0 1":                            "2br4/5pk1/6p1/8/3p4/6P1/5PK1/8 b - - 0 1",
+	} {
+		game, _ := evaluateFEN(t, fen)
+		mirroredGame, _ := evaluateFEN(t, mirrored)
+		if game.Evaluate() != mirroredGame.Evaluate() {
+			t.Errorf("%s: scores %d for white but %d mirrored for black", fen, game.Evaluate(), mirroredGame.Evaluate())
+		}
+	}
+}
+
+func TestEvaluateTerms(t *testing.T) {
+	for _, test := range []struct {
+		fen   string
+		term  Term
+		white bool
+	}{
+		// white has a passed pawn on d5, black none
+		{"4k3/p7/8/3P4/8/8/8/4K3 w - - 0 1", PASSEDPAWNS, true},
+		// black has doubled pawns on the c column
+		{"4k3/2p5/2p5/8/8/8/2P5/4K3 w - - 0 1", DOUBLEDPAWNS, true},
+		// the white pawn on a2 is isolated, black pawns on f7 and g7 are not
+		{"4k3/5pp1/8/8/8/8/P7/4K3 w - - 0 1", ISOLATEDPAWNS, false},
+		// black keeps both Bishops
+		{"2b1kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", BISHOPPAIR, false},
+		// the white Rook stands on the open d column
+		{"r3k3/p7/8/8/8/8/P7/3RK3 w - - 0 1", ROOKFILES, true},
+		// the black Queen crowds the squares around the white King
+		{"r3k3/8/8/8/8/3q4/8/4K2R w - - 0 1", KINGSAFETY, false},
+		// the white Queen in the center goes to far more squares
+		{"q3k3/8/8/8/3Q4/8/8/4K3 w - - 0 1", MOBILITY, true},
+	} {
+		_, e := evaluateFEN(t, test.fen)
+		if score := e.GetTerm(test.term); (score > 0) != test.white || score == 0 {
+			t.Errorf("%s: expected %s to favour white %t, got %d", test.fen, GetTermName(test.term), test.white, score)
+		}
+	}
+}
+
+func TestEvaluatePhase(t *testing.T) {
+	_, e := evaluateFEN(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	if e.phase != 0 {
+		t.Errorf("expected a King against a King to be in the endgame, got phase %d", e.phase)
+	}
+
+	// a centralized King is good in the endgame but bad in the middlegame
+	_, endgame := evaluateFEN(t, "4k3/8/8/8/3K4/8/8/8 w - - 0 1")
+	_, middlegame := evaluateFEN(t, "rnbqkbnr/pppppppp/8/8/3K4/8/PPPPPPPP/RNBQ1BNR w kq - 0 1")
+	if endgame.GetTermScore(PIECESQUARES, WHITE) <= 0 || middlegame.GetTermScore(PIECESQUARES, WHITE) >= middlegame.GetTermScore(PIECESQUARES, BLACK) {
+		t.Error("expected a centralized King to score by the phase of the game")
+	}
+
+	game, _ := evaluateFEN(t, "4k3/8/8/8/8/8/8/Q3K3 b - - 0 1")
+	if game.Evaluate() >= 0 {
+		t.Errorf("expected black without a Queen to score below 0, got %d", game.Evaluate())
+	}
+}
+
+func TestRunEval(t *testing.T) {
+	output := &bytes.Buffer{}
+	if err := RunEval(strings.Fields("4k3/8/8/8/8/8/8/Q3K3 w - - 0 1"), output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "material") || !strings.HasPrefix(strings.Split(output.String(), "\n")[1], "material") {
+		t.Errorf("expected the breakdown to start with material, got:\n%s", output)
+	}
+	if err := RunEval(strings.Fields("8/8/8/8/8/8/8/Q3K3 w - - 0 1"), output); err == nil {
+		t.Error("expected a position without a black King not to be evaluated")
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// InitialFEN is the initial chess position in Forsyth–Edwards Notation
+const InitialFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// fenPieces maps FEN piece letters to pieces, upper case being white
+var fenPieces = map[rune]Piece{
+	'p': PAWN,
+	'n': KNIGHT,
+	'b': BISHOP,
+	'r': ROOK,
+	'q': QUEEN,
+	'k': KING,
+}
+
+// fenCastling maps FEN castling letters to the team and side they allow
+var fenCastling = map[rune]struct {
+	team Team
+	side Side
+}{
+	'K': {WHITE, KINGSIDE},
+	'Q': {WHITE, QUEENSIDE},
+	'k': {BLACK, KINGSIDE},
+	'q': {BLACK, QUEENSIDE},
+}
+
+// NewGameFromFEN returns a Game out of a position in Forsyth–Edwards Notation
+// e.g. "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+// The halfmove and fullmove counters may be left out, as many tools do.
+func NewGameFromFEN(fen string) (Game, error) {
+	fields := strings.Fields(fen)
+	if len(fields) != 4 && len(fields) != 6 {
+		return Game{}, fmt.Errorf("invalid FEN: expected 4 or 6 fields, got %d", len(fields))
+	}
+
+	// piece placement, from rank 8 down to rank 1
+	position := Position{}
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return Game{}, fmt.Errorf("invalid FEN: expected 8 ranks, got %d", len(ranks))
+	}
+	kings := [2]int{}
+	for row, rank := range ranks {
+		col := 0
+		for _, r := range rank {
+			if r >= '1' && r <= '8' {
+				col += int(r - '0')
+				continue
+			}
+			piece, ok := fenPieces[unicode.ToLower(r)]
+			if !ok {
+				return Game{}, fmt.Errorf("invalid FEN: unknown piece %q on rank %d", r, 8-row)
+			}
+			if col >= 8 {
+				break
+			}
+			team := BLACK
+			if unicode.IsUpper(r) {
+				team = WHITE
+			}
+			if piece == KING {
+				kings[team]++
+			}
+			if piece == PAWN && (row == 0 || row == 7) {
+				return Game{}, fmt.Errorf("invalid FEN: %s pawn on rank %d, where pawns cannot stand", GetTeamName(team, LOWER), 8-row)
+			}
+			position.SetCell(row, col, NewCell(team, piece))
+			col++
+		}
+		if GetFENRankLength(rank) != 8 {
+			return Game{}, fmt.Errorf("invalid FEN: rank %d has %d squares instead of 8", 8-row, GetFENRankLength(rank))
+		}
+	}
+	if kings[WHITE] != 1 || kings[BLACK] != 1 {
+		return Game{}, fmt.Errorf("invalid FEN: expected one King per team, got %d white and %d black", kings[WHITE], kings[BLACK])
+	}
+
+	// side to move
+	turn := WHITE
+	if fields[1] == "b" {
+		turn = BLACK
+	} else if fields[1] != "w" {
+		return Game{}, fmt.Errorf("invalid FEN: side to move must be 'w' or 'b', got %q", fields[1])
+	}
+	g := NewGameFromPosition(position, turn)
+
+	// the team that just moved cannot have left its King in check
+	if g.position.IsChecked(g.GetEnemy()) {
+		return Game{}, fmt.Errorf("invalid FEN: %s is in check with %s to move", GetTeamName(g.GetEnemy(), LOWER), GetTeamName(turn, LOWER))
+	}
+
+	// castling rights, which must agree with King and Rook positions
+	inferred := g.castling
+	g.castling = [2][2]bool{}
+	if fields[2] != "-" {
+		for _, r := range fields[2] {
+			right, ok := fenCastling[r]
+			if !ok {
+				return Game{}, fmt.Errorf("invalid FEN: unknown castling right %q", r)
+			}
+			if !inferred[right.team][right.side] {
+				return Game{}, fmt.Errorf("invalid FEN: castling right %q without King and Rook on their initial squares", r)
+			}
+			g.castling[right.team][right.side] = true
+		}
+	}
+
+	// en passant square, which a pawn of the enemy must have just skipped over
+	if fields[3] != "-" {
+		location, err := GetLocationFromAlgebraic(fields[3])
+		if err != nil {
+			return Game{}, fmt.Errorf("invalid FEN: en passant square %q: %v", fields[3], err)
+		}
+		if location.row != GetHomeRow(g.GetEnemy())+2*GetPawnDirection(g.GetEnemy()) {
+			return Game{}, fmt.Errorf("invalid FEN: en passant square %q is not behind a pawn that just moved", fields[3])
+		}
+		g.enPassant = location
+		g.hasEnPassant = true
+	}
+
+	// halfmove clock and fullmove number
+	if len(fields) == 6 {
+		halfmoves, err := strconv.Atoi(fields[4])
+		if err != nil || halfmoves < 0 {
+			return Game{}, fmt.Errorf("invalid FEN: halfmove clock must be a number, got %q", fields[4])
+		}
+		fullmoves, err := strconv.Atoi(fields[5])
+		if err != nil || fullmoves < 1 {
+			return Game{}, fmt.Errorf("invalid FEN: fullmove number must be a positive number, got %q", fields[5])
+		}
+		g.halfmoves = halfmoves
+		g.fullmoves = fullmoves
+	}
+
+	return g, nil
+}
+
+// GetFENRankLength returns how many squares a rank of FEN piece placement spans
+func GetFENRankLength(rank string) int {
+	length := 0
+	for _, r := range rank {
+		if r >= '1' && r <= '9' {
+			length += int(r - '0')
+		} else {
+			length++
+		}
+	}
+	return length
+}
+
+// AsFEN returns the game position in Forsyth–Edwards Notation
+func (g Game) AsFEN() string {
+	// piece placement, from rank 8 down to rank 1
+	ranks := []string{}
+	for i := 0; i < 8; i++ {
+		rank := ""
+		empty := 0
+		for j := 0; j < 8; j++ {
+			cell := g.position.GetCell(i, j)
+			if cell.IsEmpty() {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				rank += strconv.Itoa(empty)
+				empty = 0
+			}
+			rank += GetFENPieceName(cell.GetPiece(), cell.GetTeam())
+		}
+		if empty > 0 {
+			rank += strconv.Itoa(empty)
+		}
+		ranks = append(ranks, rank)
+	}
+
+	turn := "w"
+	if g.turn == BLACK {
+		turn = "b"
+	}
+
+	castling := ""
+	for _, r := range "KQkq" {
+		right := fenCastling[r]
+		if g.CanCastle(right.team, right.side) {
+			castling += string(r)
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+
+	enPassant := "-"
+	if location, hasEnPassant := g.GetEnPassant(); hasEnPassant {
+		enPassant = GetAlgebraicFromLocation(location)
+	}
+
+	return fmt.Sprintf("%s %s %s %s %d %d", strings.Join(ranks, "/"), turn, castling, enPassant, g.halfmoves, g.fullmoves)
+}
+
+// GetFENPieceName returns the FEN letter of a piece, upper case for white
+func GetFENPieceName(piece Piece, team Team) string {
+	for r, p := range fenPieces {
+		if p == piece {
+			if team == WHITE {
+				return string(unicode.ToUpper(r))
+			}
+			return string(r)
+		}
+	}
+	return ""
+}
+
+// GetAlgebraicFromLocation returns the square in algebraic notation, e.g. "e4"
+// Unlike commands, algebraic notation counts ranks from white's side, so row
+// 7 is rank 1 and row 0 is rank 8.
+func GetAlgebraicFromLocation(location Location) string {
+	return string(rune('a'+location.col)) + strconv.Itoa(8-location.row)
+}
+
+// GetLocationFromAlgebraic returns the location of a square in algebraic notation
+func GetLocationFromAlgebraic(notation string) (Location, error) {
+	if len(notation) != 2 || !IsLetterValid(rune(notation[0])) || notation[1] < '1' || notation[1] > '8' {
+		return Location{}, fmt.Errorf("%q is not a square", notation)
+	}
+	return NewLocation(8-int(notation[1]-'0'), int(notation[0]-'a'))
+}
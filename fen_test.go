@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFENInitial(t *testing.T) {
+	game, err := NewGameFromFEN(InitialFEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := NewGame()
+	if game.position != initial.position {
+		t.Error("initial FEN board differs from initial board")
+	}
+	if initial.AsFEN() != InitialFEN {
+		t.Errorf("initial game exported as %s", initial.AsFEN())
+	}
+}
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b Kq e3 0 3",
+	}
+	for _, fen := range fens {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Errorf("%s: %v", fen, err)
+			continue
+		}
+		if game.AsFEN() != fen {
+			t.Errorf("%s exported as %s", fen, game.AsFEN())
+		}
+	}
+}
+
+func TestFENAfterMoves(t *testing.T) {
+	game := NewGame()
+	for _, command := range []string{"e7 e5", "b1 c3"} {
+		move, isValid, _, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid", command)
+		}
+		game.Execute(move)
+		if command == "e7 e5" && game.AsFEN() != "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1" {
+			t.Errorf("unexpected FEN after pawn push: %s", game.AsFEN())
+		}
+	}
+	if game.AsFEN() != "r1bqkbnr/pppppppp/2n5/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 1 2" {
+		t.Errorf("unexpected FEN after knight move: %s", game.AsFEN())
+	}
+}
+
+func TestFENEnPassant(t *testing.T) {
+	game, err := NewGameFromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// black pawn on d4 (command d5) captures e4 (command e5) en passant on e3 (command e6)
+	move, isValid, _, _ := game.NewMove("d5 e6")
+	if !isValid {
+		t.Fatal("en passant move from FEN not valid")
+	}
+	if move.strategy != ENPASSANT {
+		t.Error("en passant strategy was not identified")
+	}
+}
+
+func TestFENInvalid(t *testing.T) {
+	fens := map[string]string{
+		"":                                   "invalid FEN: expected 4 or 6 fields, got 0",
+		"8/8/8/8/8/8/8 w - - 0 1":            "invalid FEN: expected 8 ranks, got 7",
+		"4k3/8/8/8/8/8/8/4K4 w - - 0 1":      "invalid FEN: rank 1 has 9 squares instead of 8",
+		"4k3/8/8/8/8/8/8/4X3 w - - 0 1":      "invalid FEN: unknown piece 'X' on rank 1",
+		"8/8/8/8/8/8/8/4K3 w - - 0 1":        "invalid FEN: expected one King per team, got 1 white and 0 black",
+		"4k3/8/8/8/8/8/8/4K3 x - - 0 1":      "invalid FEN: side to move must be 'w' or 'b', got \"x\"",
+		"4k3/8/8/8/8/8/8/4K3 w K - 0 1":      "invalid FEN: castling right 'K' without King and Rook on their initial squares",
+		"4k3/8/8/8/8/8/8/4K3 w - e4 0 1":     "invalid FEN: en passant square \"e4\" is not behind a pawn that just moved",
+		"4k3/8/8/8/8/8/8/4K3 w - z9 0 1":     "invalid FEN: en passant square \"z9\": \"z9\" is not a square",
+		"4k3/8/8/8/8/8/8/4K3 w - - one 1":    "invalid FEN: halfmove clock must be a number, got \"one\"",
+		"4k3/8/8/8/8/8/8/4K3 w - - 0 0":      "invalid FEN: fullmove number must be a positive number, got \"0\"",
+		"P3k3/8/8/8/8/8/8/4K3 w - - 0 1":     "invalid FEN: white pawn on rank 8, where pawns cannot stand",
+		"4k3/8/8/8/8/8/8/4K2p w - - 0 1":     "invalid FEN: black pawn on rank 1, where pawns cannot stand",
+		"K7/1q6/8/8/8/8/8/7k b - - 0 1":      "invalid FEN: white is in check with black to move",
+		"4k3/8/8/8/8/8/8/4K3 w KX - 0 1":     "invalid FEN: castling right 'K' without King and Rook on their initial squares",
+		"4k3/8/8/8/8/8/8/R3K2R w X - 0 1":    "invalid FEN: unknown castling right 'X'",
+		"4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1 x": "invalid FEN: expected 4 or 6 fields, got 7",
+	}
+	for fen, expected := range fens {
+		_, err := NewGameFromFEN(fen)
+		if err == nil {
+			t.Errorf("%q: no error", fen)
+		} else if err.Error() != expected {
+			t.Errorf("%q: expected error %q, got %q", fen, expected, err.Error())
+		}
+	}
+}
+
+func TestAlgebraicNotation(t *testing.T) {
+	location, err := GetLocationFromAlgebraic("e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.row != 4 || location.col != 4 {
+		t.Errorf("e4 parsed as %d:%d", location.row, location.col)
+	}
+	if GetAlgebraicFromLocation(Location{row: 7, col: 0}) != "a1" {
+		t.Error("row 7 column 0 is not a1")
+	}
+}
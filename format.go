@@ -13,4 +13,6 @@ const (
 	UPPER
 	// LOWER is as lowercase letters, e.g. "black"
 	LOWER
+	// ALGEBRAIC is as in standard algebraic notation, e.g. "N" for a Knight
+	ALGEBRAIC
 )
@@ -0,0 +1,214 @@
+package main
+
+// Side is either side of the board a King can castle towards
+type Side int
+
+const (
+	// KINGSIDE is castling towards the h column, e.g. "e8 g8"
+	KINGSIDE Side = iota
+	// QUEENSIDE is castling towards the a column, e.g. "e8 c8"
+	QUEENSIDE
+)
+
+// Game is a chess game in progress
+// It holds the board, whose turn it is and whatever else the board alone
+// cannot tell, e.g. whether a King or a Rook has already moved.
+type Game struct {
+	position     Position
+	turn         Team
+	castling     [2][2]bool
+	enPassant    Location
+	hasEnPassant bool
+	// halfmoves counts moves since the last capture or pawn move
+	halfmoves int
+	// fullmoves counts moves of both teams, starting from 1
+	fullmoves int
+	// history holds the hashes of the positions since the last capture or pawn
+	// move, the only ones the current position can repeat
+	history []uint64
+}
+
+// Undo is what making a move loses of a game, so that it can be unmade
+// The rest is told by the move itself, e.g. the piece it moves.
+type Undo struct {
+	move         Move
+	captured     Cell
+	castling     [2][2]bool
+	enPassant    Location
+	hasEnPassant bool
+	halfmoves    int
+	history      []uint64
+}
+
+// NewGame returns a Game with all pieces in their initial chess positions
+func NewGame() Game {
+	board := Board{}
+	board.Init()
+	return NewGameFromBoard(board, WHITE)
+}
+
+// NewGameFromBoard returns a Game out of a board and the team that plays next
+// A board has no history, so castling is assumed allowed for every King and
+// Rook that still stand on their initial squares.
+func NewGameFromBoard(b Board, turn Team) Game {
+	return NewGameFromPosition(NewPositionFromBoard(b), turn)
+}
+
+// NewGameFromPosition returns a Game out of a position and the team that plays
+// next, with castling rights inferred the same way as NewGameFromBoard does
+func NewGameFromPosition(p Position, turn Team) Game {
+	g := Game{
+		position:  p,
+		turn:      turn,
+		fullmoves: 1,
+	}
+	for _, team := range []Team{WHITE, BLACK} {
+		for _, side := range []Side{KINGSIDE, QUEENSIDE} {
+			king := p.GetCell(GetHomeRow(team), 4)
+			rook := p.GetCell(GetHomeRow(team), GetRookColumn(side))
+			if king == NewCell(team, KING) && rook == NewCell(team, ROOK) {
+				g.castling[team][side] = true
+			}
+		}
+	}
+	return g
+}
+
+// GetHomeRow returns the row where the pieces of given team start from
+func GetHomeRow(team Team) int {
+	if team == WHITE {
+		return 7
+	}
+	return 0
+}
+
+// GetPawnDirection returns which way the pawns of given team move along rows
+func GetPawnDirection(team Team) int {
+	if team == WHITE {
+		return -1
+	}
+	return 1
+}
+
+// GetRookColumn returns the column where the Rook of given side starts from
+func GetRookColumn(side Side) int {
+	if side == KINGSIDE {
+		return 7
+	}
+	return 0
+}
+
+// CanCastle returns whether team is still allowed to castle towards given side
+// i.e. neither the King nor that Rook have moved or been captured.
+func (g Game) CanCastle(team Team, side Side) bool {
+	return g.castling[team][side]
+}
+
+// GetEnPassant returns the square a pawn can be captured on en passant, if any
+// That is the square a pawn skipped over with a two-square push on the
+// previous move.
+func (g Game) GetEnPassant() (Location, bool) {
+	return g.enPassant, g.hasEnPassant
+}
+
+// GetEnemy returns the team not playing next
+func (g Game) GetEnemy() Team {
+	if g.turn == WHITE {
+		return BLACK
+	}
+	return WHITE
+}
+
+// Execute applies a move to the game board and passes the turn to the enemy
+func (g *Game) Execute(m Move) {
+	g.Make(m)
+}
+
+// Make applies a move to the game board and passes the turn to the enemy
+// It returns the Undo that Unmake needs to take the move back.
+func (g *Game) Make(m Move) Undo {
+	origin := m.GetLocation(BEFORE)
+	destination := m.GetLocation(AFTER)
+	originSquare := g.position.GetSquare(m, BEFORE)
+	destinationSquare := g.position.GetSquare(m, AFTER)
+	hash := g.GetHash()
+	u := Undo{
+		move:         m,
+		captured:     g.position.Execute(m),
+		castling:     g.castling,
+		enPassant:    g.enPassant,
+		hasEnPassant: g.hasEnPassant,
+		halfmoves:    g.halfmoves,
+		history:      g.history,
+	}
+
+	// a capture or a pawn move restarts the halfmove clock
+	g.halfmoves++
+	if originSquare.piece == PAWN || !destinationSquare.isEmpty {
+		g.halfmoves = 0
+	}
+	if m.team == BLACK {
+		g.fullmoves++
+	}
+
+	// a position cannot repeat past a capture or pawn move, so history restarts
+	// there, and it is copied to leave the one kept by the Undo untouched
+	g.history = nil
+	if g.halfmoves > 0 {
+		g.history = append(u.history[:len(u.history):len(u.history)], hash)
+	}
+
+	// en passant is only possible right after a two-square pawn push
+	g.hasEnPassant = false
+	if originSquare.piece == PAWN && (destination.row-origin.row == 2 || origin.row-destination.row == 2) {
+		g.enPassant = Location{
+			row: (origin.row + destination.row) / 2,
+			col: origin.col,
+		}
+		g.hasEnPassant = true
+	}
+
+	// any move from or to a King or Rook initial square ends castling through it
+	for _, location := range []Location{m.GetLocation(BEFORE), m.GetLocation(AFTER)} {
+		for _, team := range []Team{WHITE, BLACK} {
+			if location.row != GetHomeRow(team) {
+				continue
+			}
+			if location.col == 4 {
+				g.castling[team][KINGSIDE] = false
+				g.castling[team][QUEENSIDE] = false
+			}
+			for _, side := range []Side{KINGSIDE, QUEENSIDE} {
+				if location.col == GetRookColumn(side) {
+					g.castling[team][side] = false
+				}
+			}
+		}
+	}
+
+	g.turn = m.GetEnemy()
+	return u
+}
+
+// Unmake takes back the move of given Undo, which must be the last one made
+// It restores the game exactly as it was before, special moves included.
+func (g *Game) Unmake(u Undo) {
+	g.position.Unexecute(u.move, u.captured)
+	g.castling = u.castling
+	g.enPassant = u.enPassant
+	g.hasEnPassant = u.hasEnPassant
+	g.halfmoves = u.halfmoves
+	g.history = u.history
+	if u.move.team == BLACK {
+		g.fullmoves--
+	}
+	g.turn = u.move.team
+}
+
+// GetSideName returns the name of a castling side
+func GetSideName(side Side) string {
+	if side == KINGSIDE {
+		return "kingside"
+	}
+	return "queenside"
+}
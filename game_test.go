@@ -0,0 +1,149 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGameCastlingAfterKingMoved(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"● R", "● K", "● B", "● Q", "● G", "● B", "● K", "● R"},
+		{"● P", "● P", "● P", "● P", "● P", "● P", "● P", "● P"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P"},
+		{"○ R", "○ K", "○ B", "○ Q", "○ G", "   ", "   ", "○ R"},
+	}, WHITE)
+
+	// move King away and back
+	for _, command := range []string{"e8 f8", "b2 b3", "f8 e8", "b3 b4"} {
+		move, isValid, _, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid", command)
+		}
+		game.Execute(move)
+	}
+
+	if game.CanCastle(WHITE, KINGSIDE) || game.CanCastle(WHITE, QUEENSIDE) {
+		t.Error("white can still castle after King moved")
+	}
+	if !game.CanCastle(BLACK, KINGSIDE) || !game.CanCastle(BLACK, QUEENSIDE) {
+		t.Error("black cannot castle although nothing moved")
+	}
+	_, isValid, _, _ := game.NewMove("e8 g8")
+	if isValid {
+		t.Error("King castling is valid after King moved")
+	}
+}
+
+func TestGameCastlingAfterRookCaptured(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"● R", "   ", "   ", "   ", "● G", "   ", "   ", "● R"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ R", "   ", "   ", "   ", "○ G", "   ", "   ", "○ R"},
+	}, WHITE)
+
+	move, isValid, _, _ := game.NewMove("h8 h1")
+	if !isValid {
+		t.Fatal("Rook capture move not valid")
+	}
+	game.Execute(move)
+
+	if game.CanCastle(WHITE, KINGSIDE) {
+		t.Error("white can still castle kingside after Rook moved")
+	}
+	if game.CanCastle(BLACK, KINGSIDE) {
+		t.Error("black can still castle kingside after Rook was captured")
+	}
+	if !game.CanCastle(BLACK, QUEENSIDE) {
+		t.Error("black cannot castle queenside although nothing moved")
+	}
+}
+
+func TestGameEnPassant(t *testing.T) {
+	game := NewGame()
+
+	for _, command := range []string{"e7 e5", "a2 a3", "e5 e4", "d2 d4"} {
+		move, isValid, _, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid", command)
+		}
+		game.Execute(move)
+	}
+
+	// capture black pawn that just skipped over d3
+	move, isValid, messages, _ := game.NewMove("e4 d3")
+	if !isValid {
+		t.Fatal("en passant move not valid")
+	}
+	if move.strategy != ENPASSANT {
+		t.Error("en passant strategy was not identified")
+	}
+	if messages[0] != "CAPTURE: exd6, white ○ Pawn captured ● Pawn at d5 en passant" {
+		t.Errorf("en passant message does not name captured pawn: %s", messages[0])
+	}
+	game.Execute(move)
+
+	if game.position.AsBoard()[3][3] != "   " {
+		t.Error("black pawn captured en passant is still on d4")
+	}
+	if game.position.AsBoard()[2][3] != "○ P" {
+		t.Error("white pawn did not move into d3")
+	}
+}
+
+func TestGameEnPassantOnlyNextMove(t *testing.T) {
+	game := NewGame()
+
+	for _, command := range []string{"e7 e5", "a2 a3", "e5 e4", "d2 d4", "h7 h6", "a3 a4"} {
+		move, isValid, _, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid", command)
+		}
+		game.Execute(move)
+	}
+
+	_, isValid, _, _ := game.NewMove("e4 d3")
+	if isValid {
+		t.Error("en passant move valid after another move was played")
+	}
+}
+
+// checkUnmake makes and unmakes every legal move down to given depth, and
+// fails unless each unmake restores the game exactly
+func checkUnmake(t *testing.T, game Game, depth int) {
+	if depth == 0 {
+		return
+	}
+	before := game
+	for _, m := range game.LegalMoves() {
+		u := game.Make(m)
+		checkUnmake(t, game, depth-1)
+		game.Unmake(u)
+		if !reflect.DeepEqual(game, before) {
+			t.Fatalf("unmaking %s did not restore %s, got %s", m.AsCommand(), before.AsFEN(), game.AsFEN())
+		}
+	}
+}
+
+func TestGameUnmake(t *testing.T) {
+	for _, fen := range []string{
+		InitialFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+	} {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkUnmake(t, game, 2)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+// Direction is a step on the board, e.g. one row up and one column right
+type Direction struct {
+	row int
+	col int
+}
+
+// straightDirections are the ways a Rook moves, and a Queen too
+var straightDirections = []Direction{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+// diagonalDirections are the ways a Bishop moves, and a Queen too
+var diagonalDirections = []Direction{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}}
+
+// knightJumps are the eight squares a Knight can reach
+var knightJumps = []Direction{{-2, -1}, {-2, 1}, {-1, 2}, {1, 2}, {2, -1}, {2, 1}, {-1, -2}, {1, -2}}
+
+// kingSteps are the eight squares a King can reach, all around it
+var kingSteps = []Direction{{-1, 0}, {1, 0}, {0, -1}, {0, 1}, {-1, -1}, {-1, 1}, {1, -1}, {1, 1}}
+
+// promotionPieces are the pieces a pawn can become, in order of preference
+var promotionPieces = []Piece{QUEEN, ROOK, BISHOP, KNIGHT}
+
+// LegalMoves returns every legal move of given team on the board
+// A board has no history, so castling rights are assumed for Kings and Rooks
+// on their initial squares, and en passant is never possible.
+func (b Board) LegalMoves(team Team) []Move {
+	return NewGameFromBoard(b, team).LegalMoves()
+}
+
+// LegalMoves returns every legal move of the team whose turn it is,
+// including castling, en passant and one move per promotion piece
+func (g Game) LegalMoves() []Move {
+	moves := []Move{}
+	for _, m := range g.PseudoLegalMoves() {
+		if m.IsValid(g) == "" {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// LegalCaptures returns the legal moves of the team whose turn it is that
+// change the material on the board: captures, en passant and promotions
+func (g Game) LegalCaptures() []Move {
+	moves := []Move{}
+	for _, m := range g.PseudoLegalMoves() {
+		if m.strategy != CAPTURE && m.strategy != ENPASSANT && m.strategy != PROMOTION {
+			continue
+		}
+		if m.IsValid(g) == "" {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// HasLegalMoves returns whether the team whose turn it is can move at all
+// It stops at the first legal move found, so it is cheaper than LegalMoves.
+func (g Game) HasLegalMoves() bool {
+	for _, m := range g.PseudoLegalMoves() {
+		if m.IsValid(g) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PseudoLegalMoves returns the moves the pieces of the team whose turn it is
+// can make, without checking whether they leave their own King in check
+func (g Game) PseudoLegalMoves() []Move {
+	moves := []Move{}
+	occupied := g.position.GetOccupied()
+	for piece := PAWN; piece <= KING; piece++ {
+		origins := g.position.pieces[g.turn][piece]
+		for origins != 0 {
+			square := origins.PopFirst()
+			origin := Location{row: square / 8, col: square % 8}
+
+			destinations := []Location{}
+			if piece == PAWN {
+				destinations = g.GetPawnDestinations(origin)
+			} else {
+				targets := GetPieceAttacks(piece, g.turn, square, occupied) &^ g.position.teams[g.turn]
+				destinations = targets.GetLocations()
+			}
+			if piece == KING {
+				destinations = append(destinations, g.GetCastlingDestinations(origin)...)
+			}
+
+			for _, destination := range destinations {
+				m := NewMoveFromLocations(g.position, g.turn, origin, destination)
+				if m.strategy != PROMOTION {
+					moves = append(moves, m)
+					continue
+				}
+				for _, promotion := range promotionPieces {
+					m.promotion = promotion
+					moves = append(moves, m)
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// GetCastlingDestinations returns where the King at origin may castle to
+// Only castling rights are checked here, the rest is left to validation.
+func (g Game) GetCastlingDestinations(origin Location) []Location {
+	destinations := []Location{}
+	if origin.row != GetHomeRow(g.turn) || origin.col != 4 {
+		return destinations
+	}
+	if g.CanCastle(g.turn, KINGSIDE) {
+		destinations = append(destinations, Location{row: origin.row, col: 6})
+	}
+	if g.CanCastle(g.turn, QUEENSIDE) {
+		destinations = append(destinations, Location{row: origin.row, col: 2})
+	}
+	return destinations
+}
+
+// GetPawnDestinations returns the squares the pawn at origin can move to
+// One or two squares forward when empty, diagonally forward to capture an
+// enemy piece or to the en passant square.
+func (g Game) GetPawnDestinations(origin Location) []Location {
+	destinations := []Location{}
+
+	// white moves up the rows, black down
+	forward := 1
+	startRow := 1
+	if g.turn == WHITE {
+		forward = -1
+		startRow = 6
+	}
+
+	row := origin.row + forward
+	if !IsLocationValid(row, origin.col) {
+		return destinations
+	}
+	if g.position.ParseSquare(row, origin.col).isEmpty {
+		destinations = append(destinations, Location{row: row, col: origin.col})
+		if origin.row == startRow && g.position.ParseSquare(row+forward, origin.col).isEmpty {
+			destinations = append(destinations, Location{row: row + forward, col: origin.col})
+		}
+	}
+
+	// captures are the attacked squares with an enemy piece, or en passant
+	targets := g.position.teams[g.GetEnemy()]
+	if enPassant, hasEnPassant := g.GetEnPassant(); hasEnPassant {
+		targets |= NewBitboard(enPassant)
+	}
+	targets &= pawnAttacks[g.turn][origin.row*8+origin.col]
+	return append(destinations, targets.GetLocations()...)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestLegalMovesInitial(t *testing.T) {
+	board := Board{}
+	board.Init()
+
+	moves := board.LegalMoves(WHITE)
+	if len(moves) != 20 {
+		t.Errorf("expected 20 legal moves, got %d", len(moves))
+	}
+
+	// every generated move must be accepted as a command
+	for _, m := range moves {
+		command := m.AsNotation(BEFORE) + " " + m.AsNotation(AFTER)
+		if _, isValid, _, _ := NewMove(board, WHITE, command); !isValid {
+			t.Errorf("generated move %s is not valid", command)
+		}
+	}
+}
+
+func TestLegalMovesCastling(t *testing.T) {
+	// Kiwipete position
+	board := Board{
+		{"● R", "   ", "   ", "   ", "● G", "   ", "   ", "● R"},
+		{"● P", "   ", "● P", "● P", "● Q", "● P", "● B", "   "},
+		{"● B", "● K", "   ", "   ", "● P", "● K", "● P", "   "},
+		{"   ", "   ", "   ", "○ P", "○ K", "   ", "   ", "   "},
+		{"   ", "● P", "   ", "   ", "○ P", "   ", "   ", "   "},
+		{"   ", "   ", "○ K", "   ", "   ", "○ Q", "   ", "● P"},
+		{"○ P", "○ P", "○ P", "○ B", "○ B", "○ P", "○ P", "○ P"},
+		{"○ R", "   ", "   ", "   ", "○ G", "   ", "   ", "○ R"},
+	}
+
+	moves := board.LegalMoves(WHITE)
+	if len(moves) != 48 {
+		t.Errorf("expected 48 legal moves, got %d", len(moves))
+	}
+	castlings := 0
+	for _, m := range moves {
+		if m.strategy == CASTLING {
+			castlings++
+		}
+	}
+	if castlings != 2 {
+		t.Errorf("expected 2 castling moves, got %d", castlings)
+	}
+}
+
+func TestLegalMovesPromotion(t *testing.T) {
+	board := Board{
+		{"● R", "● K", "● B", "● Q", "   ", "● G", "   ", "● R"},
+		{"● P", "● P", "   ", "○ P", "● B", "● P", "● P", "● P"},
+		{"   ", "   ", "● P", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "○ B", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "   ", "○ K", "● K", "○ P", "○ P"},
+		{"○ R", "○ K", "○ B", "○ Q", "○ G", "   ", "   ", "○ R"},
+	}
+
+	moves := board.LegalMoves(WHITE)
+	if len(moves) != 44 {
+		t.Errorf("expected 44 legal moves, got %d", len(moves))
+	}
+	promotions := 0
+	for _, m := range moves {
+		if m.strategy == PROMOTION {
+			promotions++
+		}
+	}
+	if promotions != 4 {
+		t.Errorf("expected 4 promotion moves, got %d", promotions)
+	}
+}
+
+func TestLegalMovesEnPassant(t *testing.T) {
+	game := NewGame()
+	for _, command := range []string{"e7 e5", "a2 a3", "e5 e4", "d2 d4"} {
+		move, isValid, _, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid", command)
+		}
+		game.Execute(move)
+	}
+
+	found := false
+	for _, m := range game.LegalMoves() {
+		if m.strategy == ENPASSANT && m.AsNotation(BEFORE) == "e4" && m.AsNotation(AFTER) == "d3" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("en passant move was not generated")
+	}
+}
+
+func TestLegalMovesPawnBlocked(t *testing.T) {
+	board := Board{}
+	board.Init()
+	board[5][4] = "● K"
+
+	// pawn on e7 can neither move to e6 nor jump over to e5
+	for _, m := range board.LegalMoves(WHITE) {
+		if m.AsNotation(BEFORE) == "e7" {
+			t.Errorf("blocked pawn move %s was generated", m.AsNotation(AFTER))
+		}
+	}
+	if _, isValid, _, _ := NewMove(board, WHITE, "e7 e5"); isValid {
+		t.Error("pawn jumped over a piece")
+	}
+}
@@ -2,30 +2,180 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 )
 
 func main() {
-	// initialize game
-	board := Board{}
-	board.Init()
-	turn := WHITE
-	board.Render()
+	fen := flag.String("fen", "", "start from given position in FEN instead of the initial one")
+	pgn := flag.String("pgn", time.Now().Format("chess-20060102-150405.pgn"), "file the game is saved to when it ends, none if empty")
+	load := flag.String("load", "", "resume the first game of given PGN file")
+	white := flag.String("white", "human", "who plays white, either \"human\" or \"engine\"")
+	black := flag.String("black", "human", "who plays black, either \"human\" or \"engine\"")
+	depth := flag.Int("depth", 0, "how many plies the engine searches, no limit if 0")
+	hash := flag.Int("hash", defaultHashSize, "size of the engine transposition table in megabytes")
+	movetime := flag.Duration("movetime", 5*time.Second, "how long the engine searches per move, no limit if 0")
+	tc := flag.String("tc", "", "time control, e.g. \"5+3\", \"5d3\", \"5b3\" or \"40/90+30:30+30\", none if empty")
+	flag.Parse()
 
-	// main game loop
-	for {
-		// read from stdin
-		reader := bufio.NewReader(os.Stdin)
-		turnName := GetTeamName(turn, UPPER)
-		fmt.Printf("%s plays. Enter next %s move: ", turnName, GetTeamName(turn, SYMBOL))
-		command, err := reader.ReadString('\n')
+	// run perft instead of a game, e.g. "chess perft 3"
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "perft" {
+		if err := RunPerft(args[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// replay games of a PGN file to validate them, e.g. "chess replay games.pgn"
+	if len(args) > 0 && args[0] == "replay" {
+		if err := RunReplay(args[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// count the nodes the engine searches per heuristic, e.g. "chess bench 5"
+	if len(args) > 0 && args[0] == "bench" {
+		if err := RunBench(args[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// evaluate a position term by term, e.g. "chess eval [fen]"
+	if len(args) > 0 && args[0] == "eval" {
+		if err := RunEval(args[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	game := NewGame()
+	if *fen != "" {
+		var err error
+		game, err = NewGameFromFEN(*fen)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	record := NewRecord(game)
+	if *load != "" {
+		var err error
+		record, err = LoadRecord(*load)
 		if err != nil {
-			panic(err)
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	var clock *Clock
+	if *tc != "" {
+		control, err := ParseTimeControl(*tc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		clock = NewClock(control, time.Now)
+	}
+
+	engines := [2]*Engine{}
+	for team, player := range []string{*white, *black} {
+		if player == "engine" {
+			engines[team] = NewEngine(*depth, *movetime, *hash, time.Now)
+		} else if player != "human" {
+			fmt.Printf("invalid player %q, either \"human\" or \"engine\"\n", player)
+			os.Exit(1)
+		}
+	}
+
+	result := Play(record, os.Stdin, *pgn, clock, engines)
+	fmt.Printf("RESULT: %s\n", GetResultName(result))
+}
+
+// Play runs the game loop over the game of given record, reading moves from input
+// It returns the result once the game ends, or UNFINISHED if players quit.
+// Either way, the game is saved as PGN in pgnPath, unless it is empty or the
+// players quit before any move.
+// With a clock, a team whose time runs out by the time it enters a command
+// loses, unless the enemy cannot checkmate; a nil clock plays untimed.
+// A team with an engine has its moves searched instead of read from input.
+func Play(record Record, input io.Reader, pgnPath string, clock *Clock, engines [2]*Engine) Result {
+	reader := bufio.NewReader(input)
+	game, undos := record.GetGameWithUndos()
+	// clockUndos are those of the moves played on the clock, the latest of
+	// undos, as moves loaded with the record were not
+	clockUndos := []ClockUndo{}
+	result := UNFINISHED
+	termination := ""
+	// drawOffer is the team that offered a draw, NEITHER when none is pending
+	drawOffer := NEITHER
+	// isChanged is whether the position changed since the last command, by a
+	// move or a takeback
+	isChanged := true
+	for _, engine := range engines {
+		if engine != nil {
+			engine.NewGame()
+		}
+	}
+	RenderGame(game, clock)
+	if clock != nil {
+		clock.Start(game.turn)
+	}
+
+	// a game loaded already over ends before anyone plays
+	if result = game.GetResult(); result != UNFINISHED {
+		AnnounceResult(game)
+		termination = GetTermination(game)
+	}
+
+	// main game loop
+	for result == UNFINISHED {
+		// show a pending draw offer to the team that can accept it
+		if drawOffer == game.GetEnemy() {
+			fmt.Printf("OFFER: %s offers a draw, type 'accept' or 'decline'\n", GetTeamName(drawOffer, LOWER))
+		}
+
+		// warn a player of the pieces it leaves for the enemy to win, once
+		// per position
+		if isChanged && engines[game.turn] == nil {
+			WarnHanging(game)
+		}
+		isChanged = false
+
+		// read next command
+		turnName := GetTeamName(game.turn, UPPER)
+		fmt.Printf("%s plays. Enter next %s move: ", turnName, GetTeamName(game.turn, SYMBOL))
+		command := ""
+		if engine := engines[game.turn]; engine != nil {
+			command = PlayEngine(engine, game, clock)
+		} else {
+			var err error
+			command, err = reader.ReadString('\n')
+			if err == io.EOF && len(command) == 0 {
+				fmt.Println("Goodbye!")
+				break
+			} else if err != nil && err != io.EOF {
+				panic(err)
+			}
 		}
 		command = strings.TrimSpace(command)
 
+		// check for a flag fall, which ends the game whatever the command
+		if clock != nil && clock.IsFlagged(game.turn) {
+			result, termination = FlagFall(game)
+			break
+		}
+
 		// check for exit
 		if command == "exit" || command == "quit" {
 			fmt.Println("Goodbye!")
@@ -34,46 +184,274 @@ func main() {
 
 		// check for resignation
 		if command == "resigns" {
-			winner := WHITE
-			if turn == WHITE {
-				winner = BLACK
-			}
+			winner := game.GetEnemy()
 			fmt.Printf("RESIGNATION: %s wins!\n", GetTeamName(winner, LOWER))
+			result = GetWinResult(winner)
+			termination = GetTeamName(game.turn, LOWER) + " resigns"
 			break
 		}
 
+		// check for a draw claim, e.g. "claim draw", or "claim draw Nf3" to claim
+		// a draw the move is about to allow
+		isClaim := false
+		if command == "claim draw" {
+			if rule := ClaimDraw(game); rule != NODRAW {
+				result = DRAW
+				termination = "draw by " + GetDrawRuleName(rule)
+				break
+			}
+			continue
+		} else if strings.HasPrefix(command, "claim draw ") {
+			isClaim = true
+			command = strings.TrimSpace(strings.TrimPrefix(command, "claim draw "))
+		}
+
+		// check for a draw offer, which stands until the enemy moves
+		if command == "offer draw" {
+			if drawOffer != NEITHER {
+				fmt.Println("OFFER: invalid; a draw is already offered")
+				continue
+			}
+			drawOffer = game.turn
+			fmt.Printf("OFFER: %s offers a draw\n", GetTeamName(game.turn, LOWER))
+			continue
+		}
+
+		// check for an answer to the draw the enemy offered
+		if command == "accept" || command == "decline" {
+			if drawOffer != game.GetEnemy() {
+				fmt.Printf("OFFER: invalid; no draw offer to %s\n", command)
+				continue
+			}
+			if command == "accept" {
+				fmt.Printf("DRAW: by %s\n", GetDrawRuleName(AGREEMENT))
+				result = DRAW
+				termination = "draw by " + GetDrawRuleName(AGREEMENT)
+				break
+			}
+			drawOffer = NEITHER
+			fmt.Printf("OFFER: %s declines the draw\n", GetTeamName(game.turn, LOWER))
+			continue
+		}
+
+		// check for the evaluation of the position, term by term
+		if command == "eval" {
+			PrintEvaluation(game, os.Stdout)
+			continue
+		}
+
+		// check for the moves played so far
+		if command == "history" {
+			fmt.Printf("HISTORY: %s\n", record.AsMoveList())
+			continue
+		}
+
+		// check for taking back the last move, e.g. to correct a blunder
+		// Against an engine, its reply is taken back too, or it would play the
+		// same reply again right away.
+		if command == "takeback" {
+			plies := 1
+			if engines[game.GetEnemy()] != nil {
+				plies = 2
+			}
+			if len(undos) < plies {
+				fmt.Println("TAKEBACK: no move to take back")
+				continue
+			}
+			// the time used until the takeback is charged in the stage it was
+			// used in, before the moves taken back leave it
+			if clock != nil {
+				clock.Start(game.turn)
+			}
+			taken := []string{}
+			for i := 0; i < plies; i++ {
+				taken = append([]string{record.TakeBack()}, taken...)
+				game.Unmake(undos[len(undos)-1])
+				undos = undos[:len(undos)-1]
+				if len(clockUndos) > 0 {
+					clock.TakeBack(clockUndos[len(clockUndos)-1])
+					clockUndos = clockUndos[:len(clockUndos)-1]
+				}
+			}
+			drawOffer = NEITHER
+			isChanged = true
+			if clock != nil {
+				clock.Start(game.turn)
+			}
+			RenderGame(game, clock)
+			fmt.Printf("TAKEBACK: %s taken back\n", strings.Join(taken, " "))
+			continue
+		}
+
+		// check for saving the game so far, e.g. "save game.pgn"
+		if strings.HasPrefix(command, "save ") {
+			SaveRecord(record, strings.TrimSpace(strings.TrimPrefix(command, "save ")))
+			continue
+		}
+
 		// create move
-		move, isValid, messages, isEndgame := NewMove(board, turn, command)
+		move, isValid, messages, isEndgame := game.NewMove(command)
 
 		// check move validity
 		if !isValid {
-			board.Render()
+			RenderGame(game, clock)
 			if len(messages) > 0 {
 				fmt.Printf("%s\n", messages[0])
 			}
 			continue
 		}
 
-		// execute move
-		board.Execute(move)
+		// a draw offer lapses when the enemy moves instead of answering it
+		if drawOffer == game.GetEnemy() {
+			drawOffer = NEITHER
+		}
+
+		// execute move, which also passes the turn
+		record.Add(game, move)
+		undos = append(undos, game.Make(move))
+		isChanged = true
+		if clock != nil {
+			clockUndos = append(clockUndos, clock.Press())
+		}
 
 		// render new board
-		board.Render()
+		RenderGame(game, clock)
 
-		// show status message, start from i=1
-		for i := 1; i < len(messages); i++ {
-			fmt.Printf("%s\n", messages[i])
+		// show the move in SAN, then any status message
+		for _, message := range messages {
+			fmt.Printf("%s\n", message)
 		}
 
 		if isEndgame {
+			result, termination = game.GetResult(), GetTermination(game)
 			break
 		}
 
-		// change turns
-		if turn == WHITE {
-			turn = BLACK
-		} else {
-			turn = WHITE
+		// a claim with a move is decided on the position the move leads to, and
+		// the move stands either way
+		if isClaim {
+			if rule := ClaimDraw(game); rule != NODRAW {
+				result = DRAW
+				termination = "draw by " + GetDrawRuleName(rule)
+				break
+			}
 		}
 	}
+
+	record.End(result, termination)
+	// a game left before any move is not worth a file
+	if pgnPath != "" && (len(record.moves) > 0 || result != UNFINISHED) {
+		SaveRecord(record, pgnPath)
+	}
+	return result
+}
+
+// RenderGame prints the game position in stdout, with the time each team has
+// left beside its home row when there is a clock
+func RenderGame(game Game, clock *Clock) {
+	if clock == nil {
+		game.position.Render()
+		return
+	}
+	notes := [8]string{}
+	for _, team := range []Team{WHITE, BLACK} {
+		notes[GetHomeRow(team)] = fmt.Sprintf("%s %s", GetTeamName(team, SYMBOL), FormatClock(clock.GetRemaining(team)))
+	}
+	b := game.position.AsBoard()
+	b.RenderBeside(notes)
+}
+
+// PlayEngine has the engine search the move of the team to play, and returns
+// it as the command a player would type, having echoed it after the prompt
+// With a clock, the engine keeps time for about 20 more moves.
+func PlayEngine(engine *Engine, game Game, clock *Clock) string {
+	timeLimit := engine.moveTime
+	if clock != nil {
+		budget := clock.GetRemaining(game.turn) / 20
+		if timeLimit == 0 || budget < timeLimit {
+			timeLimit = budget
+		}
+	}
+	search := engine.Search(game, timeLimit)
+	if search.depth == 0 {
+		fmt.Println("quit")
+		fmt.Println("ENGINE: no legal move to play")
+		return "quit"
+	}
+	command := search.move.AsCommand()
+	fmt.Println(command)
+	fmt.Printf("ENGINE: %s, score %s at depth %d, %d nodes\n", game.GetSAN(search.move), FormatScore(search.score), search.depth, search.nodes)
+	return command
+}
+
+// WarnHanging tells the team to play which of its pieces the enemy wins
+// material by capturing, if any
+func WarnHanging(game Game) {
+	hanging := []string{}
+	for _, location := range game.position.GetHangingPieces(game.turn) {
+		piece := game.position.ParseSquare(location.row, location.col).piece
+		hanging = append(hanging, fmt.Sprintf("%s at %s", GetPieceName(piece, VERBOSE), GetAlgebraicFromLocation(location)))
+	}
+	if len(hanging) > 0 {
+		fmt.Printf("HANGING: %s %s\n", GetTeamName(game.turn, VERBOSE), strings.Join(hanging, ", "))
+	}
+}
+
+// AnnounceResult tells the players how given game ended, once it is over
+func AnnounceResult(game Game) {
+	result := game.GetResult()
+	if result != DRAW {
+		fmt.Printf("CHECKMATE: %s wins!\n", GetTeamName(game.GetEnemy(), LOWER))
+	} else if game.IsStalemated() {
+		fmt.Println("STALEMATE: draw")
+	} else {
+		fmt.Printf("DRAW: by %s\n", GetDrawRuleName(game.GetAutomaticDraw()))
+	}
+}
+
+// GetTermination returns how given game ended, once it is over, e.g.
+// "stalemate", for its PGN record; a checkmate needs none
+func GetTermination(game Game) string {
+	if game.GetResult() != DRAW {
+		return ""
+	}
+	if game.IsStalemated() {
+		return "stalemate"
+	}
+	return "draw by " + GetDrawRuleName(game.GetAutomaticDraw())
+}
+
+// FlagFall tells the players that the team to play ran out of time
+// It returns the result and termination of the game, a draw when the enemy
+// does not have the pieces left to checkmate.
+func FlagFall(game Game) (Result, string) {
+	loser := GetTeamName(game.turn, LOWER)
+	winner := game.GetEnemy()
+	if !game.position.HasMatingMaterial(winner) {
+		fmt.Printf("TIME: %s runs out of time, but %s cannot checkmate: draw\n", loser, GetTeamName(winner, LOWER))
+		return DRAW, loser + " runs out of time, " + GetTeamName(winner, LOWER) + " cannot checkmate"
+	}
+	fmt.Printf("TIME: %s runs out of time, %s wins!\n", loser, GetTeamName(winner, LOWER))
+	return GetWinResult(winner), loser + " forfeits on time"
+}
+
+// ClaimDraw tells the players whether a draw claim on given game holds
+// It returns the rule the draw is claimed by, or NODRAW when it does not hold.
+func ClaimDraw(game Game) DrawRule {
+	rule := game.GetClaimableDraw()
+	if rule == NODRAW {
+		fmt.Println("CLAIM: invalid; no threefold repetition, nor 50 moves without a capture or pawn move")
+		return NODRAW
+	}
+	fmt.Printf("DRAW: by %s\n", GetDrawRuleName(rule))
+	return rule
+}
+
+// SaveRecord saves the game record as PGN and tells the players where
+func SaveRecord(record Record, path string) {
+	if err := record.Save(path); err != nil {
+		fmt.Printf("SAVE: failed; %s\n", err)
+		return
+	}
+	fmt.Printf("SAVE: game written to %s\n", path)
 }
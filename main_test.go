@@ -0,0 +1,300 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlayCheckmate(t *testing.T) {
+	input := strings.NewReader("f7 f6\ne2 e4\ng7 g5\nd1 h5\n")
+	result := Play(NewRecord(NewGame()), input, "", nil, [2]*Engine{})
+	if result != BLACKWINS {
+		t.Errorf("expected black to win, got %s", GetResultName(result))
+	}
+}
+
+func TestPlayResignation(t *testing.T) {
+	input := strings.NewReader("e7 e5\nresigns\n")
+	result := Play(NewRecord(NewGame()), input, "", nil, [2]*Engine{})
+	if result != WHITEWINS {
+		t.Errorf("expected white to win, got %s", GetResultName(result))
+	}
+}
+
+func TestPlayQuit(t *testing.T) {
+	input := strings.NewReader("e7 e5\nquit\n")
+	result := Play(NewRecord(NewGame()), input, "", nil, [2]*Engine{})
+	if result != UNFINISHED {
+		t.Errorf("expected unfinished game, got %s", GetResultName(result))
+	}
+}
+
+func TestPlayQuitBeforeMove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// nothing is exported when the players quit before any move
+	path := filepath.Join(dir, "game.pgn")
+	Play(NewRecord(NewGame()), strings.NewReader("history\nquit\n"), path, nil, [2]*Engine{})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no exported game, got %v", err)
+	}
+}
+
+func TestPlayGameOver(t *testing.T) {
+	for fen, expected := range map[string]Result{
+		// black is checkmated
+		"R6k/8/6K1/8/8/8/8/8 b - - 0 1": WHITEWINS,
+		// black is stalemated
+		"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1": DRAW,
+		// neither team can checkmate
+		"7k/8/6K1/8/8/8/8/8 w - - 0 1": DRAW,
+	} {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := Play(NewRecord(game), strings.NewReader(""), "", nil, [2]*Engine{})
+		if result != expected {
+			t.Errorf("%s: expected %s, got %s", fen, GetResultName(expected), GetResultName(result))
+		}
+	}
+}
+
+func TestPlaySavesPGN(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	savePath := filepath.Join(dir, "saved.pgn")
+	endPath := filepath.Join(dir, "end.pgn")
+	input := strings.NewReader("e7 e5\nsave " + savePath + "\ne2 e4\nresigns\n")
+	Play(NewRecord(NewGame()), input, endPath, nil, [2]*Engine{})
+
+	saved, err := ioutil.ReadFile(savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(saved), "1. e4 *\n") {
+		t.Errorf("unexpected saved game:\n%s", saved)
+	}
+	ended, err := ioutil.ReadFile(endPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(ended), "1. e4 e5 {white resigns} 0-1\n") {
+		t.Errorf("unexpected exported game:\n%s", ended)
+	}
+}
+
+func TestPlayResumesPGN(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "game.pgn")
+	if err := ioutil.WriteFile(path, []byte("1. f3 e5 2. g4 *\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	record, err := LoadRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// black mates on the resumed game
+	result := Play(record, strings.NewReader("d1 h5\n"), path, nil, [2]*Engine{})
+	if result != BLACKWINS {
+		t.Errorf("expected black to win, got %s", GetResultName(result))
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "1. f3 e5 2. g4 Qh4# 0-1\n") {
+		t.Errorf("unexpected exported game:\n%s", content)
+	}
+}
+
+func TestPlayTakeback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// white takes back a blunder that allows mate, then black takes back too
+	path := filepath.Join(dir, "game.pgn")
+	input := strings.NewReader("f7 f6\ne2 e4\ntakeback\ntakeback\ntakeback\ntakeback\ne7 e5\ne2 e4\ntakeback\nquit\n")
+	result := Play(NewRecord(NewGame()), input, path, nil, [2]*Engine{})
+	if result != UNFINISHED {
+		t.Errorf("expected unfinished game, got %s", GetResultName(result))
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "1. e4 *\n") {
+		t.Errorf("unexpected exported game:\n%s", content)
+	}
+}
+
+func TestPlayClaimDraw(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a claim too early fails, and one with the move that repeats holds
+	path := filepath.Join(dir, "game.pgn")
+	input := strings.NewReader("Nf3\nNf6\nNg1\nNg8\nclaim draw\nNf3\nNf6\nNg1\nclaim draw Ng8\n")
+	result := Play(NewRecord(NewGame()), input, path, nil, [2]*Engine{})
+	if result != DRAW {
+		t.Errorf("expected a draw, got %s", GetResultName(result))
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "Ng8 {draw by threefold repetition}\n1/2-1/2\n") {
+		t.Errorf("unexpected exported game:\n%s", content)
+	}
+}
+
+func TestPlayDrawByAgreement(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "game.pgn")
+	input := strings.NewReader("offer draw\ne7 e5\naccept\n")
+	result := Play(NewRecord(NewGame()), input, path, nil, [2]*Engine{})
+	if result != DRAW {
+		t.Errorf("expected a draw, got %s", GetResultName(result))
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(content), "1. e4 {draw by agreement} 1/2-1/2\n") {
+		t.Errorf("unexpected exported game:\n%s", content)
+	}
+}
+
+func TestPlayDrawOfferLapses(t *testing.T) {
+	for _, commands := range []string{
+		// black moves instead of answering, so the offer lapses
+		"offer draw\ne7 e5\ne2 e4\naccept\nquit\n",
+		// black declines, so there is nothing left to accept
+		"offer draw\ne7 e5\ndecline\naccept\nquit\n",
+		// white cannot accept its own offer
+		"offer draw\naccept\nquit\n",
+	} {
+		result := Play(NewRecord(NewGame()), strings.NewReader(commands), "", nil, [2]*Engine{})
+		if result != UNFINISHED {
+			t.Errorf("%q: expected unfinished game, got %s", commands, GetResultName(result))
+		}
+	}
+}
+
+// timedInput gives one line per read, advancing a fake time by the delay of
+// the line first, as if the player took that long to type it
+type timedInput struct {
+	source *fakeTime
+	lines  []string
+	delays []time.Duration
+}
+
+func (ti *timedInput) Read(p []byte) (int, error) {
+	if len(ti.lines) == 0 {
+		return 0, io.EOF
+	}
+	ti.source.now = ti.source.now.Add(ti.delays[0])
+	n := copy(p, ti.lines[0]+"\n")
+	ti.lines, ti.delays = ti.lines[1:], ti.delays[1:]
+	return n, nil
+}
+
+func TestPlayFlagFall(t *testing.T) {
+	for fen, expected := range map[string]Result{
+		// black runs out of time and white has a Rook to checkmate with
+		"4k3/8/8/8/8/8/8/R3K3 w - - 0 1": WHITEWINS,
+		// black runs out of time, but a lone King cannot checkmate
+		"4k2r/8/8/8/8/8/8/4K3 w - - 0 1": DRAW,
+	} {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source := &fakeTime{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+		control, err := ParseTimeControl("1+0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := &timedInput{
+			source: source,
+			lines:  []string{"Kd1", "Kd8"},
+			delays: []time.Duration{10 * time.Second, 70 * time.Second},
+		}
+		result := Play(NewRecord(game), input, "", NewClock(control, source.Now), [2]*Engine{})
+		if result != expected {
+			t.Errorf("%s: expected %s, got %s", fen, GetResultName(expected), GetResultName(result))
+		}
+	}
+}
+
+func TestPlayEngine(t *testing.T) {
+	// white plays the back rank mate for the human
+	game, err := NewGameFromFEN("6k1/5ppp/8/8/8/8/8/R5K1 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engines := [2]*Engine{WHITE: NewEngine(2, 0, 1, time.Now)}
+	result := Play(NewRecord(game), strings.NewReader("Kh8\n"), "", nil, engines)
+	if result != WHITEWINS {
+		t.Errorf("expected white to win, got %s", GetResultName(result))
+	}
+
+	// a takeback against the engine takes back its reply and the human move
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "game.pgn")
+	engines = [2]*Engine{BLACK: NewEngine(1, 0, 1, time.Now)}
+	input := strings.NewReader("e7 e5\ntakeback\ntakeback\nd7 d5\nquit\n")
+	Play(NewRecord(NewGame()), input, path, nil, engines)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "e4") || !strings.Contains(string(content), "1. d4 ") {
+		t.Errorf("unexpected exported game:\n%s", content)
+	}
+
+	// two engines play on their own
+	game, err = NewGameFromFEN("7k/8/8/8/8/8/1R6/KR6 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engines = [2]*Engine{NewEngine(3, 0, 1, time.Now), NewEngine(1, 0, 1, time.Now)}
+	result = Play(NewRecord(game), strings.NewReader(""), "", nil, engines)
+	if result != WHITEWINS {
+		t.Errorf("expected white to win, got %s", GetResultName(result))
+	}
+}
@@ -15,6 +15,8 @@ type Move struct {
 	beforeNumber int
 	afterLetter  rune
 	afterNumber  int
+	// promotion is the piece a pawn is promoted to, PAWN when none is given
+	promotion Piece
 }
 
 // Part defines either the before or the after part of a move
@@ -53,15 +55,32 @@ const (
 // Also returns whether Move was valid, a possible message for user, and whether is
 // final move.
 func NewMove(b Board, team Team, command string) (Move, bool, []string, bool) {
+	g := NewGameFromBoard(b, team)
+	return g.NewMove(command)
+}
+
+// NewMove validates and returns a new Move struct out of a command string,
+// played by the team whose turn it is in the game
+func (g Game) NewMove(command string) (Move, bool, []string, bool) {
+	p := g.position
+	team := g.turn
+	original := command
 	if len(command) == 4 {
 		command = string(command[0]) + string(command[1]) + " " + string(command[2]) + string(command[3])
+	} else if len(command) == 5 && !strings.Contains(command, " ") {
+		command = string(command[0]) + string(command[1]) + " " + string(command[2]) + string(command[3]) + " " + string(command[4])
 	}
 
+	// not a command, but maybe a move in SAN, e.g. "Nf3"
 	if !IsCommandValid(command) {
-		isValid := false
-		isEndgame := false
-		messages := []string{"MOVE: invalid; example: 'd7 d6'"}
-		return Move{}, isValid, messages, isEndgame
+		sanMove, err := g.ParseSAN(original)
+		if err != nil {
+			isValid := false
+			isEndgame := false
+			messages := []string{"MOVE: " + err.Error()}
+			return Move{}, isValid, messages, isEndgame
+		}
+		command = sanMove.AsCommand()
 	}
 
 	// parse command
@@ -86,10 +105,14 @@ func NewMove(b Board, team Team, command string) (Move, bool, []string, bool) {
 	}
 	m.afterNumber = afterNumber
 
-	m.strategy = GetStrategy(m, b)
+	if len(words) == 3 {
+		m.promotion, _ = GetPromotionPiece(words[2])
+	}
+
+	m.strategy = GetStrategy(m, p)
 
 	// check move validity
-	validityMessage := m.IsValid(b, team)
+	validityMessage := m.IsValid(g)
 	if len(validityMessage) > 0 {
 		isValid := false
 		isEndgame := false
@@ -97,36 +120,79 @@ func NewMove(b Board, team Team, command string) (Move, bool, []string, bool) {
 		return Move{}, isValid, messages, isEndgame
 	}
 
-	// build success message
-	destinationLocation := m.AsNotation(AFTER)
-	originSquare := b.GetSquare(m, BEFORE)
+	// build success message, which leads with the move in SAN, and names
+	// squares with the same ranks
+	san := g.GetSANWithoutSuffix(m)
+	destinationLocation := GetAlgebraicFromLocation(m.GetLocation(AFTER))
+	originSquare := p.GetSquare(m, BEFORE)
 	originPieceName := GetPieceName(originSquare.piece, VERBOSE)
 	originTeamName := GetTeamName(m.team, VERBOSE)
-	destinationSquare := b.GetSquare(m, AFTER)
+	destinationSquare := p.GetSquare(m, AFTER)
 	capturedPieceName := GetPieceName(destinationSquare.piece, VERBOSE)
 	destinationTeamName := GetTeamName(m.GetEnemy(), SYMBOL)
-	msg := fmt.Sprintf("MOVE: %s %s moved to %s", originTeamName, originPieceName, destinationLocation)
+	msg := fmt.Sprintf("%s moved to %s", originPieceName, destinationLocation)
+	kind := "MOVE"
 	if m.strategy == CAPTURE {
-		msg = fmt.Sprintf("CAPTURE: %s %s captured %s %s at %s", originTeamName, originPieceName, destinationTeamName, capturedPieceName, destinationLocation)
+		kind = "CAPTURE"
+		msg = fmt.Sprintf("%s captured %s %s at %s", originPieceName, destinationTeamName, capturedPieceName, destinationLocation)
+	} else if m.strategy == ENPASSANT {
+		kind = "CAPTURE"
+		capturedLocation := GetAlgebraicFromLocation(m.GetEnPassantCapture())
+		msg = fmt.Sprintf("%s captured %s Pawn at %s en passant", originPieceName, destinationTeamName, capturedLocation)
+	} else if m.strategy == CASTLING {
+		kind = "CASTLING"
+		msg = fmt.Sprintf("%s castled %s", originPieceName, GetSideName(m.GetSide()))
+	} else if m.strategy == PROMOTION {
+		kind = "PROMOTION"
+		promotionPieceName := GetPieceName(m.promotion, VERBOSE)
+		msg = fmt.Sprintf("%s moved to %s and promoted to %s", originPieceName, destinationLocation, promotionPieceName)
+		if !destinationSquare.isEmpty {
+			msg = fmt.Sprintf("%s captured %s %s at %s and promoted to %s", originPieceName, destinationTeamName, capturedPieceName, destinationLocation, promotionPieceName)
+		}
 	}
 
 	// check if move causes enemy to be in check
-	inCheck := IsInCheck(b, m, m.GetEnemy())
-
-	// check if move causes enemy to lose
-	checkmated := IsCheckmated(b, m, m.GetEnemy())
+	inCheck := p.IsCheckedAfter(m, m.GetEnemy())
+
+	// check if move causes enemy to lose, or leaves it without moves
+	nextGame := g
+	nextGame.Execute(m)
+	result := nextGame.GetResult()
+	if result == GetWinResult(m.team) {
+		san += "#"
+	} else if inCheck {
+		san += "+"
+	}
 
-	// handle check and checkmate messages
-	messages := []string{msg}
-	if checkmated {
+	// handle check, checkmate and stalemate messages
+	messages := []string{fmt.Sprintf("%s: %s, %s %s", kind, san, originTeamName, msg)}
+	if result == GetWinResult(m.team) {
 		checkmateMessage := fmt.Sprintf("CHECKMATE: %s wins!", GetTeamName(m.team, LOWER))
 		messages = append(messages, checkmateMessage)
+	} else if result == DRAW && nextGame.IsStalemated() {
+		messages = append(messages, "STALEMATE: draw")
+	} else if result == DRAW {
+		messages = append(messages, "DRAW: by "+GetDrawRuleName(nextGame.GetAutomaticDraw()))
 	} else if inCheck {
 		checkMessage := fmt.Sprintf("CHECK: %s is in check", destinationTeamName)
 		messages = append(messages, checkMessage)
 	}
 
-	return m, true, messages, checkmated
+	return m, true, messages, result != UNFINISHED
+}
+
+// NewMoveFromLocations returns the move of team from one location to another
+// The move is not validated, only its strategy is identified on given position.
+func NewMoveFromLocations(p Position, team Team, before Location, after Location) Move {
+	m := Move{
+		team:         team,
+		beforeLetter: rune('a' + before.col),
+		beforeNumber: before.row + 1,
+		afterLetter:  rune('a' + after.col),
+		afterNumber:  after.row + 1,
+	}
+	m.strategy = GetStrategy(m, p)
+	return m
 }
 
 // GetLocation returns the Location struct of either BEFORE or AFTER parts
@@ -138,19 +204,9 @@ func (m Move) GetLocation(part Part) Location {
 	}
 
 	// col
-	columnLetters := map[rune]int{
-		'a': 0,
-		'b': 1,
-		'c': 2,
-		'd': 3,
-		'e': 4,
-		'f': 5,
-		'g': 6,
-		'h': 7,
-	}
-	col := columnLetters[m.beforeLetter]
-	if part == AFTER {
-		col = columnLetters[m.afterLetter]
+	col := int(m.afterLetter - 'a')
+	if part == BEFORE {
+		col = int(m.beforeLetter - 'a')
 	}
 
 	return Location{
@@ -168,6 +224,16 @@ func (m Move) AsNotation(part Part) string {
 	return string(m.afterLetter) + strconv.Itoa(m.afterNumber)
 }
 
+// AsCommand returns the move as a command a player would type
+// e.g. "d7 d6", or "e2 e1 Q" when a pawn is promoted
+func (m Move) AsCommand() string {
+	command := m.AsNotation(BEFORE) + " " + m.AsNotation(AFTER)
+	if m.strategy == PROMOTION {
+		command += " " + GetPieceName(m.promotion, SYMBOL)
+	}
+	return command
+}
+
 // GetEnemy return the player color not playing current move
 func (m Move) GetEnemy() Team {
 	if m.team == WHITE {
@@ -179,13 +245,19 @@ func (m Move) GetEnemy() Team {
 // IsCommandValid returns whether a command is valid
 // A command is a chess move notation
 // e.g. "d7 d6", which means piece that is at d7, should go to d6
+// or "e2 e1 Q", which means pawn that is at e2, should go to e1 and become a Queen
 func IsCommandValid(command string) bool {
 	words := strings.Fields(command)
 
-	// check that there are two words
-	if len(words) != 2 {
+	// check that there are two words, or three with a promotion piece
+	if len(words) != 2 && len(words) != 3 {
 		return false
 	}
+	if len(words) == 3 {
+		if _, ok := GetPromotionPiece(words[2]); !ok {
+			return false
+		}
+	}
 
 	before := words[0]
 	after := words[1]
@@ -246,7 +318,7 @@ func IsNumberValid(number rune) bool {
 
 	beforeNumberInt, err := strconv.Atoi(numberStr)
 	if err != nil {
-		panic(err)
+		return false
 	}
 	valid := false
 	for _, n := range validNumbers {
@@ -258,24 +330,40 @@ func IsNumberValid(number rune) bool {
 }
 
 // GetStrategy identifies what strategy player goes for
-func GetStrategy(m Move, b Board) Strategy {
-	beforeSquare := b.GetSquare(m, BEFORE)
-	afterSquare := b.GetSquare(m, AFTER)
+func GetStrategy(m Move, p Position) Strategy {
+	beforeSquare := p.GetSquare(m, BEFORE)
+	afterSquare := p.GetSquare(m, AFTER)
+	beforeLocation := m.GetLocation(BEFORE)
+	afterLocation := m.GetLocation(AFTER)
+	if !beforeSquare.isEmpty && beforeSquare.piece == KING && beforeLocation.row == afterLocation.row {
+		if afterLocation.col-beforeLocation.col == 2 || beforeLocation.col-afterLocation.col == 2 {
+			return CASTLING
+		}
+	}
+	if !beforeSquare.isEmpty && beforeSquare.piece == PAWN && afterLocation.row == GetHomeRow(m.GetEnemy()) {
+		return PROMOTION
+	}
 	if beforeSquare.team != afterSquare.team && !afterSquare.isEmpty {
 		return CAPTURE
 	}
+	if !beforeSquare.isEmpty && beforeSquare.piece == PAWN && beforeLocation.col != afterLocation.col {
+		return ENPASSANT
+	}
 	return NORMAL
 }
 
-// IsValid checks whether the move is valid, given board and whose turn it is
-func (m Move) IsValid(b Board, turn Team) string {
+// IsValid checks whether the move is valid, given the game board and whose turn it is
+func (m Move) IsValid(g Game) string {
+	p := g.position
+	turn := g.turn
+
 	// handle same origin and destination
 	if m.GetLocation(BEFORE).row == m.GetLocation(AFTER).row && m.GetLocation(BEFORE).col == m.GetLocation(AFTER).col {
 		return "invalid; origin and destination are the same"
 	}
 
 	// handle empty square on origin
-	beforeSquare := b.GetSquare(m, BEFORE)
+	beforeSquare := p.GetSquare(m, BEFORE)
 	if beforeSquare.isEmpty {
 		return "invalid; empty origin"
 	}
@@ -286,25 +374,47 @@ func (m Move) IsValid(b Board, turn Team) string {
 	}
 
 	// handle when player's destination is same color
-	afterSquare := b.GetSquare(m, AFTER)
+	afterSquare := p.GetSquare(m, AFTER)
 	if afterSquare.team == turn {
 		return "invalid; destination is same color"
 	}
 
+	// castling is validated on its own, as it moves two pieces
+	if m.strategy == CASTLING {
+		return m.IsCastlingValid(g)
+	}
+
+	// only a pawn reaching the last row is promoted, and it must say to what
+	if m.strategy == PROMOTION && m.promotion == PAWN {
+		return "invalid; choose a promotion piece, e.g. '" + m.AsNotation(BEFORE) + " " + m.AsNotation(AFTER) + " Q'"
+	}
+	if m.strategy != PROMOTION && m.promotion != PAWN {
+		return "invalid; only a pawn reaching the last row can be promoted"
+	}
+
+	// en passant is only possible on the square a pawn just skipped over
+	if m.strategy == ENPASSANT {
+		enPassant, hasEnPassant := g.GetEnPassant()
+		destination := m.GetLocation(AFTER)
+		if !hasEnPassant || enPassant.row != destination.row || enPassant.col != destination.col {
+			return "invalid; no pawn to capture en passant"
+		}
+	}
+
 	originPiece := beforeSquare.piece
 	validity := false
 	if originPiece == ROOK {
-		validity = m.IsRookMoveValid(b)
+		validity = m.IsRookMoveValid(p)
 	} else if originPiece == KNIGHT {
-		validity = m.IsKnightMoveValid(b)
+		validity = m.IsKnightMoveValid(p)
 	} else if originPiece == BISHOP {
-		validity = m.IsBishopMoveValid(b)
+		validity = m.IsBishopMoveValid(p)
 	} else if originPiece == QUEEN {
-		validity = m.IsQueenMoveValid(b)
+		validity = m.IsQueenMoveValid(p)
 	} else if originPiece == KING {
-		validity = m.IsKingMoveValid(b)
+		validity = m.IsKingMoveValid(p)
 	} else if originPiece == PAWN {
-		validity = m.IsPawnMoveValid(b)
+		validity = m.IsPawnMoveValid(p)
 	}
 
 	if validity == false {
@@ -312,7 +422,7 @@ func (m Move) IsValid(b Board, turn Team) string {
 	}
 
 	// check if player playing now is in check
-	if IsInCheck(b, m, m.team) {
+	if p.IsCheckedAfter(m, m.team) {
 		return "invalid as checked"
 	}
 
@@ -322,626 +432,241 @@ func (m Move) IsValid(b Board, turn Team) string {
 // GetNotationFromLocation returns string of notation, given Location
 func GetNotationFromLocation(location Location) string {
 	notationRow := location.row + 1
-
-	colNotations := map[int]rune{
-		0: 'a',
-		1: 'b',
-		2: 'c',
-		3: 'd',
-		4: 'e',
-		5: 'f',
-		6: 'g',
-		7: 'h',
-	}
-	notationCol := colNotations[location.col]
+	notationCol := rune('a' + location.col)
 
 	return string(notationCol) + strconv.Itoa(notationRow)
 }
 
 // IsInCheck returns true if possiblyCheckedTeam is in check, after given move has been executed
-// To find the answer, it scans all board squares, creates moves with each
-// enemy piece as origin and current team King as destination, and then checks
-// if the move is valid. If so, then that means it's a capture move, which means
-// current team's King is in check position.
 func IsInCheck(b Board, m Move, possiblyCheckedTeam Team) bool {
-	var newBoard Board
-	newBoard.LoadData(b)
-	newBoard.Execute(m)
+	p := NewPositionFromBoard(b)
+	return p.IsCheckedAfter(m, possiblyCheckedTeam)
+}
+
+// IsChecked returns true if possiblyCheckedTeam is in check on current board
+func (b Board) IsChecked(possiblyCheckedTeam Team) bool {
+	return NewPositionFromBoard(b).IsChecked(possiblyCheckedTeam)
+}
 
-	// find attacker team
+// IsChecked returns true if possiblyCheckedTeam is in check in the position
+// That is when any enemy piece attacks the square of its King, which takes a
+// lookup in the attack tables per kind of piece.
+func (p Position) IsChecked(possiblyCheckedTeam Team) bool {
+	king := p.pieces[possiblyCheckedTeam][KING]
+	if king == 0 {
+		return false
+	}
 	attackerTeam := WHITE
 	if possiblyCheckedTeam == WHITE {
 		attackerTeam = BLACK
 	}
-	possiblyCheckedKingLocation := newBoard.FindKing(possiblyCheckedTeam)
-	possiblyCheckedKingLocationAsNotation := GetNotationFromLocation(possiblyCheckedKingLocation)
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			attackerOriginSquare := newBoard.ParseSquare(i, j)
-
-			// omit empty origin squares
-			if attackerOriginSquare.isEmpty {
-				continue
-			}
-
-			// omit moves with origin the possibly checked team
-			if attackerOriginSquare.team != attackerTeam {
-				continue
-			}
-
-			currentLocation := Location{
-				row: i,
-				col: j,
-			}
-			if currentLocation.row == possiblyCheckedKingLocation.row && currentLocation.col == possiblyCheckedKingLocation.col {
-				continue
-			}
-			currentLocationAsNotation := GetNotationFromLocation(currentLocation)
-			command := currentLocationAsNotation + " " + possiblyCheckedKingLocationAsNotation
-
-			// parse command
-			words := strings.Fields(command)
-			before := words[0]
-			after := words[1]
-
-			// build move to test if it is a check move
-			testCheckMove := Move{}
-			testCheckMove.team = attackerTeam
-			testCheckMove.beforeLetter = []rune(before)[0]
-			testCheckMove.afterLetter = []rune(after)[0]
-			beforeNumber, err := strconv.Atoi(string([]rune(before)[1]))
-			if err != nil {
-				panic(err)
-			}
-			testCheckMove.beforeNumber = beforeNumber
-			afterNumber, err := strconv.Atoi(string([]rune(after)[1]))
-			if err != nil {
-				panic(err)
-			}
-			testCheckMove.afterNumber = afterNumber
-			testCheckMove.strategy = GetStrategy(m, newBoard)
-
-			// validate move
-			piece := attackerOriginSquare.piece
-			validity := false
-			if piece == ROOK {
-				validity = testCheckMove.IsRookMoveValid(newBoard)
-			} else if piece == KNIGHT {
-				validity = testCheckMove.IsKnightMoveValid(newBoard)
-			} else if piece == BISHOP {
-				validity = testCheckMove.IsBishopMoveValid(newBoard)
-			} else if piece == QUEEN {
-				validity = testCheckMove.IsQueenMoveValid(newBoard)
-			} else if piece == KING {
-				validity = testCheckMove.IsKingMoveValid(newBoard)
-			} else if piece == PAWN {
-				validity = testCheckMove.IsPawnMoveValid(newBoard)
-			}
-
-			// if move is valid, then it means King is in check position
-			// which means that we should return true
-			// if not, then we should continue searching
-			if validity {
-				return true
-			}
-
-		}
-	}
-
-	return false
+	return p.IsAttacked(king.First(), attackerTeam)
 }
 
-// IsCheckmated returns true if given team loses
-// It works by finding given team's King and checking if it has any valid moves
-func IsCheckmated(b Board, m Move, possiblyCheckmatedTeam Team) bool {
-	// create new board and apply current move
-	var newBoard Board
-	newBoard.LoadData(b)
-	newBoard.Execute(m)
-
-	// find all possible destination locations for possibly checkmated King
-	possiblyCheckmatedKingLocation := newBoard.FindKing(possiblyCheckmatedTeam)
-	kingDestinations := []Location{
-		// clockwise
-		{row: possiblyCheckmatedKingLocation.row - 1, col: possiblyCheckmatedKingLocation.col},
-		{row: possiblyCheckmatedKingLocation.row - 1, col: possiblyCheckmatedKingLocation.col + 1},
-		{row: possiblyCheckmatedKingLocation.row, col: possiblyCheckmatedKingLocation.col + 1},
-		{row: possiblyCheckmatedKingLocation.row + 1, col: possiblyCheckmatedKingLocation.col + 1},
-		{row: possiblyCheckmatedKingLocation.row + 1, col: possiblyCheckmatedKingLocation.col},
-		{row: possiblyCheckmatedKingLocation.row + 1, col: possiblyCheckmatedKingLocation.col - 1},
-		{row: possiblyCheckmatedKingLocation.row, col: possiblyCheckmatedKingLocation.col - 1},
-		{row: possiblyCheckmatedKingLocation.row - 1, col: possiblyCheckmatedKingLocation.col - 1},
-	}
-
-	// check if move with origin the King and destination each possible King destination
-	// is valid, if no valid are found, then King has been checkmated
-	foundValid := false
-	for _, currentLocation := range kingDestinations {
-		if !IsLocationValid(currentLocation.row, currentLocation.col) {
-			continue
-		}
-
-		possiblyCheckmatedKingLocationAsNotation := GetNotationFromLocation(possiblyCheckmatedKingLocation)
-		currentLocationAsNotation := GetNotationFromLocation(currentLocation)
-		command := possiblyCheckmatedKingLocationAsNotation + " " + currentLocationAsNotation
-
-		// parse command
-		words := strings.Fields(command)
-		before := words[0]
-		after := words[1]
-
-		// build move to test if it is a check move
-		testKingMove := Move{}
-		testKingMove.team = possiblyCheckmatedTeam
-		testKingMove.beforeLetter = []rune(before)[0]
-		testKingMove.afterLetter = []rune(after)[0]
-		beforeNumber, err := strconv.Atoi(string([]rune(before)[1]))
-		if err != nil {
-			panic(err)
-		}
-		testKingMove.beforeNumber = beforeNumber
-		afterNumber, err := strconv.Atoi(string([]rune(after)[1]))
-		if err != nil {
-			panic(err)
-		}
-		testKingMove.afterNumber = afterNumber
-		testKingMove.strategy = GetStrategy(m, newBoard)
-
-		// validate move
-		isMoveValid := testKingMove.IsKingMoveValid(newBoard)
-		if isMoveValid {
-			// validate move for check status too
-			// if in check, then move not valid
-			isMoveValid = !IsInCheck(newBoard, testKingMove, testKingMove.team)
-		}
-
-		// if valid move found, then King not checkmated
-		if isMoveValid {
-			foundValid = true
-			break
-		}
+// IsCastlingValid checks whether a castling move is valid in given game
+// The King and the Rook must not have moved, the squares between them must be
+// empty, and the King may not be in check, pass through check or land in check.
+func (m Move) IsCastlingValid(g Game) string {
+	p := g.position
+	origin := m.GetLocation(BEFORE)
+	homeRow := GetHomeRow(m.team)
+	if origin.row != homeRow || origin.col != 4 {
+		return "invalid move"
 	}
 
-	// return not foundValid because
-	// if valid Move is found, means King is not checkmated (thus return false)
-	// if no valid Move is found, means King is checkmated (thus return true)
-	return !foundValid
-}
-
-// IsRookMoveValid returns whether given move, with Rook as origin piece, is valid
-func (m Move) IsRookMoveValid(b Board) bool {
-	originLocation := m.GetLocation(BEFORE)
-	destinationLocation := m.GetLocation(AFTER)
-
-	// top
-	newRow := originLocation.row - 1
-	for IsLocationValid(newRow, originLocation.col) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(newRow, originLocation.col).isEmpty {
-				// path is not clear, break
-				break
-			}
-			if originLocation.col == destinationLocation.col && newRow == destinationLocation.row {
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(newRow, originLocation.col).isEmpty {
-				// path is not clear, either found or break
-				if originLocation.col == destinationLocation.col && newRow == destinationLocation.row {
-					return true
-				}
-				break
-			}
-		}
-		newRow--
-	}
-
-	// bottom
-	newRow = originLocation.row + 1
-	for IsLocationValid(newRow, originLocation.col) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(newRow, originLocation.col).isEmpty {
-				// path is not clear, break
-				break
-			}
-			if originLocation.col == destinationLocation.col && newRow == destinationLocation.row {
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(newRow, originLocation.col).isEmpty {
-				// path is not clear, either found or break
-				if originLocation.col == destinationLocation.col && newRow == destinationLocation.row {
-					return true
-				}
-				break
-			}
-		}
-		newRow++
-	}
-
-	// left
-	newCol := originLocation.col - 1
-	for IsLocationValid(originLocation.row, newCol) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(originLocation.row, newCol).isEmpty {
-				// path is not clear, break
-				break
-			}
-			if originLocation.row == destinationLocation.row && newCol == destinationLocation.col {
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(originLocation.row, newCol).isEmpty {
-				// path is not clear, either found or break
-				if originLocation.row == destinationLocation.row && newCol == destinationLocation.col {
-					return true
-				}
-				break
-			}
-		}
-		newCol--
-	}
-
-	// right
-	newCol = originLocation.col + 1
-	for IsLocationValid(originLocation.row, newCol) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(originLocation.row, newCol).isEmpty {
-				// path is not clear, break
-				break
-			}
-			if originLocation.row == destinationLocation.row && newCol == destinationLocation.col {
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(originLocation.row, newCol).isEmpty {
-				// path is not clear, either found or break
-				if originLocation.row == destinationLocation.row && newCol == destinationLocation.col {
-					return true
-				}
-				break
-			}
-		}
-		newCol++
+	side := m.GetSide()
+	if !g.CanCastle(m.team, side) {
+		return "invalid; King or Rook has already moved"
+	}
+	rookSquare := p.ParseSquare(homeRow, GetRookColumn(side))
+	if rookSquare.team != m.team || rookSquare.piece != ROOK {
+		return "invalid; no Rook to castle with"
 	}
 
-	return false
-}
-
-// IsKnightMoveValid returns whether given move, with Knight as origin piece, is valid
-func (m Move) IsKnightMoveValid(b Board) bool {
-	// searching for Knight moves in the fashion of
-	// two hops forward, then one left, or one right
-	originLocation := m.GetLocation(BEFORE)
-	destinationLocation := m.GetLocation(AFTER)
-
-	// handle top hand
-	newRow := originLocation.row - 2
-	newCol := originLocation.col - 1 // left side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+	// every square between King and Rook must be empty
+	step := 1
+	if side == QUEENSIDE {
+		step = -1
 	}
-	newCol = originLocation.col + 1 // right side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
+	for col := origin.col + step; col != GetRookColumn(side); col += step {
+		if !p.ParseSquare(homeRow, col).isEmpty {
+			return "invalid; castling path is not clear"
 		}
 	}
 
-	// handle right hand
-	newCol = originLocation.col + 2
-	newRow = originLocation.row - 1 // top side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
-	}
-	newRow = originLocation.row + 1 // bottom side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+	if p.IsChecked(m.team) {
+		return "invalid; cannot castle out of check"
 	}
 
-	// handle bottom hand
-	newRow = originLocation.row + 2
-	newCol = originLocation.col - 1 // left side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+	// King passes through the square next to it, which must not be attacked
+	passMove := Move{
+		team:         m.team,
+		strategy:     NORMAL,
+		beforeLetter: m.beforeLetter,
+		beforeNumber: m.beforeNumber,
+		afterLetter:  m.beforeLetter + rune(step),
+		afterNumber:  m.afterNumber,
 	}
-	newCol = originLocation.col + 1 // right side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+	if p.IsCheckedAfter(passMove, m.team) {
+		return "invalid; cannot castle through check"
 	}
 
-	// handle left hand
-	newCol = originLocation.col - 2
-	newRow = originLocation.row - 1 // top side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
-	}
-	newRow = originLocation.row + 1 // bottom side
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+	if p.IsCheckedAfter(m, m.team) {
+		return "invalid as checked"
 	}
 
-	return false
+	return ""
 }
 
-// IsBishopMoveValid returns whether given move, with Bishop as origin piece, is valid
-func (m Move) IsBishopMoveValid(b Board) bool {
-	originLocation := m.GetLocation(BEFORE)
-	destinationLocation := m.GetLocation(AFTER)
-
-	// go top-right
-	newRow := originLocation.row - 1
-	newCol := originLocation.col + 1
-	for IsLocationValid(newRow, newCol) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear
-				break
-			}
-			if newRow == destinationLocation.row && newCol == destinationLocation.col {
-				// reached destination
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear, either found or break
-				if newCol == destinationLocation.col && newRow == destinationLocation.row {
-					return true
-				}
-				break
-			}
-		}
-
-		newRow = newRow - 1
-		newCol = newCol + 1
-	}
-
-	// go bottom-right
-	newRow = originLocation.row + 1
-	newCol = originLocation.col + 1
-	for IsLocationValid(newRow, newCol) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear
-				break
-			}
-			if newRow == destinationLocation.row && newCol == destinationLocation.col {
-				// reached destination
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear, either found or break
-				if newCol == destinationLocation.col && newRow == destinationLocation.row {
-					return true
-				}
-				break
-			}
-		}
-		newRow = newRow + 1
-		newCol = newCol + 1
-	}
-
-	// go bottom-left
-	newRow = originLocation.row + 1
-	newCol = originLocation.col - 1
-	for IsLocationValid(newRow, newCol) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear
-				break
-			}
-			if newRow == destinationLocation.row && newCol == destinationLocation.col {
-				// reached destination
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear, either found or break
-				if newCol == destinationLocation.col && newRow == destinationLocation.row {
-					return true
-				}
-				break
-			}
-		}
-		newRow = newRow + 1
-		newCol = newCol - 1
-	}
-
-	// go top-left
-	newRow = originLocation.row - 1
-	newCol = originLocation.col - 1
-	for IsLocationValid(newRow, newCol) {
-		if m.strategy == NORMAL {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear
-				break
-			}
-			if newRow == destinationLocation.row && newCol == destinationLocation.col {
-				// reached destination
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			if !b.ParseSquare(newRow, newCol).isEmpty {
-				// path is not clear, either found or break
-				if newCol == destinationLocation.col && newRow == destinationLocation.row {
-					return true
-				}
-				break
-			}
-		}
-		newRow = newRow - 1
-		newCol = newCol - 1
+// GetEnPassantCapture returns where the pawn captured en passant stands
+// That is beside the origin, on the column of the destination.
+func (m Move) GetEnPassantCapture() Location {
+	return Location{
+		row: m.GetLocation(BEFORE).row,
+		col: m.GetLocation(AFTER).col,
 	}
+}
 
-	return false
+// GetSide returns towards which side a castling move goes
+func (m Move) GetSide() Side {
+	if m.GetLocation(AFTER).col > m.GetLocation(BEFORE).col {
+		return KINGSIDE
+	}
+	return QUEENSIDE
 }
 
-// IsQueenMoveValid returns whether given move, with Queen as origin piece, is valid
-func (m Move) IsQueenMoveValid(b Board) bool {
-	rookMovesValidity := m.IsRookMoveValid(b)
-	bishopMovesValidity := m.IsBishopMoveValid(b)
+// IsCheckmated returns true if given team loses, after given move has been executed
+func IsCheckmated(b Board, m Move, possiblyCheckmatedTeam Team) bool {
+	g := NewGameFromBoard(b, m.team)
+	g.Execute(m)
+	g.turn = possiblyCheckmatedTeam
 
-	return rookMovesValidity || bishopMovesValidity
+	return g.IsCheckmated()
 }
 
-// IsKingMoveValid returns whether given move, with King as origin piece, is valid
-func (m Move) IsKingMoveValid(b Board) bool {
-	originLocation := m.GetLocation(BEFORE)
-	destinationLocation := m.GetLocation(AFTER)
-
-	// vertical top
-	newRow := originLocation.row - 1
-	newCol := originLocation.col
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+// IsCheckmated returns true if the team whose turn it is loses
+// That is when it is in check and none of its pieces has a legal move.
+func (g Game) IsCheckmated() bool {
+	if !g.position.IsChecked(g.turn) {
+		return false
 	}
+	return !g.HasLegalMoves()
+}
 
-	// diagonal top-right
-	newRow = originLocation.row - 1
-	newCol = originLocation.col + 1
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+// IsStalemated returns true if the team whose turn it is cannot move
+// That is when it is not in check but none of its pieces has a legal move.
+func (g Game) IsStalemated() bool {
+	if g.position.IsChecked(g.turn) {
+		return false
 	}
+	return !g.HasLegalMoves()
+}
 
-	// horizontal right
-	newRow = originLocation.row
-	newCol = originLocation.col + 1
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
+// GetResult returns the result of the game as it stands on the board
+// The team whose turn it is loses when checkmated, and draws when stalemated
+// or when a rule ends the game without a claim, e.g. fivefold repetition.
+func (g Game) GetResult() Result {
+	if !g.HasLegalMoves() {
+		if g.position.IsChecked(g.turn) {
+			return GetWinResult(g.GetEnemy())
 		}
+		return DRAW
 	}
-
-	// diagonal bottom-right
-	newRow = originLocation.row + 1
-	newCol = originLocation.col + 1
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+	if g.GetAutomaticDraw() != NODRAW {
+		return DRAW
 	}
+	return UNFINISHED
+}
 
-	// vertical bottom
-	newRow = originLocation.row + 1
-	newCol = originLocation.col
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
-	}
+// IsRookMoveValid returns whether given move, with Rook as origin piece, is valid
+func (m Move) IsRookMoveValid(p Position) bool {
+	origin := m.GetLocation(BEFORE)
+	return m.IsSlidingMoveValid(p, GetRookAttacks(origin.row*8+origin.col, p.GetOccupied()))
+}
 
-	// diagonal bottom-left
-	newRow = originLocation.row + 1
-	newCol = originLocation.col - 1
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
-	}
+// IsKnightMoveValid returns whether given move, with Knight as origin piece, is valid
+func (m Move) IsKnightMoveValid(p Position) bool {
+	origin := m.GetLocation(BEFORE)
+	return m.IsStepMoveValid(knightAttacks[origin.row*8+origin.col])
+}
 
-	// horizontal left
-	newRow = originLocation.row
-	newCol = originLocation.col - 1
-	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+// IsBishopMoveValid returns whether given move, with Bishop as origin piece, is valid
+func (m Move) IsBishopMoveValid(p Position) bool {
+	origin := m.GetLocation(BEFORE)
+	return m.IsSlidingMoveValid(p, GetBishopAttacks(origin.row*8+origin.col, p.GetOccupied()))
+}
+
+// IsQueenMoveValid returns whether given move, with Queen as origin piece, is valid
+func (m Move) IsQueenMoveValid(p Position) bool {
+	origin := m.GetLocation(BEFORE)
+	return m.IsSlidingMoveValid(p, GetQueenAttacks(origin.row*8+origin.col, p.GetOccupied()))
+}
+
+// IsKingMoveValid returns whether given move, with King as origin piece, is valid
+func (m Move) IsKingMoveValid(p Position) bool {
+	origin := m.GetLocation(BEFORE)
+	return m.IsStepMoveValid(kingAttacks[origin.row*8+origin.col])
+}
+
+// IsSlidingMoveValid returns whether a sliding piece reaches the destination,
+// given the squares it attacks
+// The attacks stop at the first piece on each ray, so a normal move must land
+// on an empty square and a capture on an occupied one.
+func (m Move) IsSlidingMoveValid(p Position, attacks Bitboard) bool {
+	destination := m.GetLocation(AFTER)
+	square := destination.row*8 + destination.col
+	if !attacks.Has(square) {
+		return false
+	}
+	if m.strategy == NORMAL {
+		return !p.GetOccupied().Has(square)
+	} else if m.strategy == CAPTURE {
+		return p.GetOccupied().Has(square)
 	}
+	return false
+}
 
-	// diagonal top-left
-	newRow = originLocation.row - 1
-	newCol = originLocation.col - 1
+// IsStepMoveValid returns whether a Knight or King reaches the destination,
+// given the squares it attacks
+func (m Move) IsStepMoveValid(attacks Bitboard) bool {
+	destination := m.GetLocation(AFTER)
 	if m.strategy == NORMAL || m.strategy == CAPTURE {
-		if newRow == destinationLocation.row && newCol == destinationLocation.col {
-			return true
-		}
+		return attacks.Has(destination.row*8 + destination.col)
 	}
-
 	return false
 }
 
 // IsPawnMoveValid returns whether given move, with Pawn as origin piece, is valid
-func (m Move) IsPawnMoveValid(b Board) bool {
+func (m Move) IsPawnMoveValid(p Position) bool {
 	originLocation := m.GetLocation(BEFORE)
 	destinationLocation := m.GetLocation(AFTER)
 
-	// find out if pawn is on first move
-	firstMove := false
-	if (originLocation.row == 1 && m.team == BLACK) || (originLocation.row == 6 && m.team == WHITE) {
-		firstMove = true
+	// promotion moves either straight or captures diagonally
+	strategy := m.strategy
+	if strategy == PROMOTION {
+		strategy = NORMAL
+		if !p.GetSquare(m, AFTER).isEmpty {
+			strategy = CAPTURE
+		}
 	}
 
-	// if white / down side
-	if m.team == WHITE {
-		newRow := originLocation.row - 1
-		if m.strategy == NORMAL {
-			if newRow == destinationLocation.row && originLocation.col == destinationLocation.col {
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			newColLeft := originLocation.col - 1
-			if newRow == destinationLocation.row && newColLeft == destinationLocation.col {
-				return true
-			}
-			newColRight := originLocation.col + 1
-			if newRow == destinationLocation.row && newColRight == destinationLocation.col {
-				return true
-			}
-		}
-		if firstMove {
-			newRow--
-			if m.strategy == NORMAL {
-				if newRow == destinationLocation.row && originLocation.col == destinationLocation.col {
-					return true
-				}
-			}
-		}
+	// captures go diagonally forward, one of the squares the pawn attacks
+	if strategy == CAPTURE || strategy == ENPASSANT {
+		return pawnAttacks[m.team][originLocation.row*8+originLocation.col].Has(destinationLocation.row*8 + destinationLocation.col)
+	}
+	if strategy != NORMAL || originLocation.col != destinationLocation.col {
+		return false
 	}
 
-	// if black / up side
-	if m.team == BLACK {
-		newRow := originLocation.row + 1
-		if m.strategy == NORMAL {
-			if newRow == destinationLocation.row && originLocation.col == destinationLocation.col {
-				return true
-			}
-		} else if m.strategy == CAPTURE {
-			newColLeft := originLocation.col + 1
-			if newRow == destinationLocation.row && newColLeft == destinationLocation.col {
-				return true
-			}
-			newColRight := originLocation.col - 1
-			if newRow == destinationLocation.row && newColRight == destinationLocation.col {
-				return true
-			}
-		}
-		if firstMove {
-			newRow++
-			if m.strategy == NORMAL {
-				if newRow == destinationLocation.row && originLocation.col == destinationLocation.col {
-					return true
-				}
-			}
-		}
+	// one square forward, or two on the first move when nothing is in between
+	direction := GetPawnDirection(m.team)
+	newRow := originLocation.row + direction
+	if newRow == destinationLocation.row {
+		return true
+	}
+	firstMove := originLocation.row == GetHomeRow(m.team)+direction
+	if firstMove && p.ParseSquare(newRow, originLocation.col).isEmpty && newRow+direction == destinationLocation.row {
+		return true
 	}
 
 	return false
@@ -894,3 +894,317 @@ func TestMoveCheckmate(t *testing.T) {
 		t.Error("endgame move not identified")
 	}
 }
+
+func TestCastlingKingside(t *testing.T) {
+	board := Board{
+		{"● R", "● K", "● B", "● Q", "● G", "● B", "● K", "● R"},
+		{"● P", "● P", "● P", "● P", "● P", "● P", "● P", "● P"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P"},
+		{"○ R", "○ K", "○ B", "○ Q", "○ G", "   ", "   ", "○ R"},
+	}
+
+	// create move
+	turn := WHITE
+	command := "e8 g8"
+	move, isValid, _, _ := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("King kingside castling not valid")
+	}
+	if move.strategy != CASTLING {
+		t.Error("King castling strategy was not identified")
+	}
+}
+
+func TestCastlingQueenside(t *testing.T) {
+	board := Board{
+		{"● R", "   ", "   ", "   ", "● G", "● B", "● K", "● R"},
+		{"● P", "● P", "● P", "● P", "● P", "● P", "● P", "● P"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P"},
+		{"○ R", "○ K", "○ B", "○ Q", "○ G", "○ B", "○ K", "○ R"},
+	}
+
+	// create move
+	turn := BLACK
+	command := "e1 c1"
+	move, isValid, _, _ := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("King queenside castling not valid")
+	}
+	if move.strategy != CASTLING {
+		t.Error("King castling strategy was not identified")
+	}
+}
+
+func TestCastlingInvalidPathNotClear(t *testing.T) {
+	board := Board{
+		{"● R", "● K", "● B", "● Q", "● G", "● B", "● K", "● R"},
+		{"● P", "● P", "● P", "● P", "● P", "● P", "● P", "● P"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P", "○ P"},
+		{"○ R", "○ K", "   ", "   ", "○ G", "○ B", "○ K", "○ R"},
+	}
+
+	// create move
+	turn := WHITE
+	command := "e8 c8"
+	_, isValid, _, _ := NewMove(board, turn, command)
+	if isValid {
+		t.Error("King castling is valid while a Knight stands in between")
+	}
+}
+
+func TestCastlingInvalidThroughCheck(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "   ", "● G", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "● R", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ R", "   ", "   ", "   ", "○ G", "   ", "   ", "○ R"},
+	}
+
+	// create move
+	turn := WHITE
+	command := "e8 g8"
+	_, isValid, _, _ := NewMove(board, turn, command)
+	if isValid {
+		t.Error("King castling is valid while passing through check")
+	}
+
+	// the other side is not attacked
+	command = "e8 c8"
+	_, isValid, _, _ = NewMove(board, turn, command)
+	if !isValid {
+		t.Error("King queenside castling not valid")
+	}
+}
+
+func TestCastlingInvalidInCheck(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "   ", "● G", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "● R", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ R", "   ", "   ", "   ", "○ G", "   ", "   ", "○ R"},
+	}
+
+	// create move
+	turn := WHITE
+	command := "e8 g8"
+	_, isValid, _, _ := NewMove(board, turn, command)
+	if isValid {
+		t.Error("King castling is valid while in check")
+	}
+}
+
+func TestPawnPromotion(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "○ P", "   ", "   ", "● G"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "○ G", "   ", "   ", "   "},
+	}
+
+	// create move
+	turn := WHITE
+	command := "e2 e1 Q"
+	move, isValid, _, _ := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("Pawn promotion move not valid")
+	}
+	if move.strategy != PROMOTION {
+		t.Error("Pawn promotion strategy was not identified")
+	}
+
+	// execute move
+	board.Execute(move)
+	if board[0][4] != "○ Q" {
+		t.Error("white pawn was not promoted to a queen on e1")
+	}
+}
+
+func TestPawnPromotionCompact(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "● R", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "○ P", "   ", "   ", "● G"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "○ G", "   ", "   ", "   "},
+	}
+
+	// create move, capturing and underpromoting to a knight
+	turn := WHITE
+	command := "e2d1k"
+	move, isValid, _, _ := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("Pawn capture promotion move not valid")
+	}
+
+	// execute move
+	board.Execute(move)
+	if board[0][3] != "○ K" {
+		t.Error("white pawn was not promoted to a knight on d1")
+	}
+}
+
+func TestPawnPromotionInvalidWithoutPiece(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "● G"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "● P", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "○ G"},
+	}
+
+	// create move
+	turn := BLACK
+	command := "e7 e8"
+	_, isValid, messages, _ := NewMove(board, turn, command)
+	if isValid {
+		t.Error("Pawn promotion move valid without a promotion piece")
+	}
+	if messages[0] != "MOVE: invalid; choose a promotion piece, e.g. 'e7 e8 Q'" {
+		t.Errorf("unclear message for missing promotion piece: %s", messages[0])
+	}
+}
+
+func TestPawnPromotionInvalidNotLastRow(t *testing.T) {
+	board := Board{}
+	board.Init()
+
+	// create move
+	turn := WHITE
+	command := "e7 e6 Q"
+	_, isValid, _, _ := NewMove(board, turn, command)
+	if isValid {
+		t.Error("Pawn promotion valid before reaching the last row")
+	}
+}
+
+func TestMoveCheckNotCheckmateAsCapturable(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "   ", "○ G", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "○ B"},
+		{"   ", "   ", "   ", "   ", "● G", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "● R", "   "},
+	}
+
+	// create move, the Rook can be captured by the Bishop
+	turn := BLACK
+	command := "g8 g1"
+	move, isValid, messages, isEndgame := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("invalid Rook check move")
+	}
+	if IsCheckmated(board, move, WHITE) || isEndgame {
+		t.Error("checkmate identified although checking Rook can be captured")
+	}
+	if messages[len(messages)-1] != "CHECK: ○ is in check" {
+		t.Error("check move not identified")
+	}
+}
+
+func TestMoveCheckNotCheckmateAsBlockable(t *testing.T) {
+	board := Board{
+		{"   ", "   ", "   ", "   ", "○ G", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "● G", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "○ R", "● R", "   "},
+	}
+
+	// create move, the check can be blocked by the Rook on f8
+	turn := BLACK
+	command := "g8 g1"
+	_, isValid, _, isEndgame := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("invalid Rook check move")
+	}
+	if isEndgame {
+		t.Error("checkmate identified although check can be blocked")
+	}
+}
+
+func TestMoveNotCheckmateWithoutCheck(t *testing.T) {
+	board := Board{
+		{"○ G", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "● Q", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "○ P", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "● G", "   ", "   ", "   "},
+	}
+
+	// create move, which boxes the King in but gives no check
+	turn := BLACK
+	command := "c3 b3"
+	move, isValid, _, _ := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("invalid Queen move")
+	}
+	if IsCheckmated(board, move, WHITE) {
+		t.Error("checkmate identified without check")
+	}
+}
+
+func TestMoveStalemate(t *testing.T) {
+	board := Board{
+		{"● G", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "○ Q", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "○ G"},
+	}
+
+	// create move, which leaves black King without any move
+	turn := WHITE
+	command := "b5 b3"
+	_, isValid, messages, isEndgame := NewMove(board, turn, command)
+	if !isValid {
+		t.Error("invalid Queen move")
+	}
+	if !isEndgame {
+		t.Error("stalemate move not identified as endgame")
+	}
+	if messages[len(messages)-1] != "STALEMATE: draw" {
+		t.Error("stalemate message not shown")
+	}
+}
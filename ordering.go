@@ -0,0 +1,199 @@
+package main
+
+// Heuristic is a way of ordering moves so that the best ones are searched
+// first, for alpha-beta to cut off the rest sooner
+type Heuristic int
+
+const (
+	// HASHMOVE puts first the best move the transposition table holds
+	HASHMOVE Heuristic = 1 << iota
+	// MVVLVA puts captures next, the most valuable victim first and, among
+	// those, the least valuable attacker
+	MVVLVA
+	// SEE puts the captures that lose material, once the exchange plays out,
+	// after the quiet moves
+	SEE
+	// KILLERS puts next the quiet moves that cut off a sibling position
+	KILLERS
+	// COUNTERMOVES puts next the quiet move that last cut off the enemy move
+	// just played
+	COUNTERMOVES
+	// HISTORY sorts the remaining quiet moves by how often they cut off,
+	// weighted by depth
+	HISTORY
+)
+
+// allHeuristics are all the heuristics, which an engine uses by default
+const allHeuristics = HASHMOVE | MVVLVA | SEE | KILLERS | COUNTERMOVES | HISTORY
+
+// heuristics are all the heuristics, in the order they rank moves
+var heuristics = []Heuristic{HASHMOVE, MVVLVA, SEE, KILLERS, COUNTERMOVES, HISTORY}
+
+// GetHeuristicName returns the name of a heuristic, e.g. "killers"
+func GetHeuristicName(heuristic Heuristic) string {
+	heuristicNames := map[Heuristic]string{
+		HASHMOVE:     "hash move",
+		MVVLVA:       "MVV-LVA",
+		SEE:          "SEE",
+		KILLERS:      "killers",
+		COUNTERMOVES: "countermoves",
+		HISTORY:      "history",
+	}
+	return heuristicNames[heuristic]
+}
+
+const (
+	// the ranks of each kind of move, apart enough for the ones below never
+	// to catch up
+	hashMoveRank    = 1 << 30
+	captureRank     = 1 << 24
+	losingRank      = -1 << 24
+	killerRank      = 1 << 22
+	counterMoveRank = 1 << 21
+	// maxHistory is where history scores are halved, to stay below the ranks
+	// above
+	maxHistory = 1 << 20
+)
+
+// victimRanks are how much capturing each piece is worth to MVV-LVA, by Piece
+var victimRanks = [6]int{
+	PAWN:   1,
+	KNIGHT: 2,
+	BISHOP: 3,
+	ROOK:   4,
+	QUEEN:  5,
+	KING:   6,
+}
+
+// Ordering is what an engine learns during a search about which quiet moves
+// are good, to order moves by
+type Ordering struct {
+	// killers are the two latest quiet moves that cut off, by ply
+	killers [maxPly + 1][2]Move
+	// counterMoves are the quiet moves that cut off the enemy move, by the
+	// squares the enemy moved from and to
+	counterMoves [64][64]Move
+	// history is how well each quiet move did, by team and squares from and to
+	history [2][64][64]int
+}
+
+// NewSearch forgets the killers, which are only worth it in the position the
+// search started from, and fades the history
+func (o *Ordering) NewSearch() {
+	o.killers = [maxPly + 1][2]Move{}
+	o.FadeHistory()
+}
+
+// FadeHistory halves the history of every move, for the latest cutoffs to
+// weigh more than the older ones
+func (o *Ordering) FadeHistory() {
+	for team := range o.history {
+		for from := range o.history[team] {
+			for to := range o.history[team][from] {
+				o.history[team][from][to] /= 2
+			}
+		}
+	}
+}
+
+// GetMoveSquares returns the squares a move goes from and to
+func GetMoveSquares(m Move) (int, int) {
+	from := m.GetLocation(BEFORE)
+	to := m.GetLocation(AFTER)
+	return from.row*8 + from.col, to.row*8 + to.col
+}
+
+// IsQuiet returns whether a move changes no material, neither capturing nor
+// promoting
+func (m Move) IsQuiet() bool {
+	return m.strategy == NORMAL || m.strategy == CASTLING
+}
+
+// GetMVVLVA returns the rank of a capture or promotion by MVV-LVA: the more
+// valuable the victim the better, and the less valuable the attacker
+func GetMVVLVA(p Position, m Move) int {
+	from, to := GetMoveSquares(m)
+	victim := 0
+	if m.strategy == ENPASSANT {
+		victim = victimRanks[PAWN]
+	} else if cell := p.cells[to]; cell != EMPTY {
+		victim = victimRanks[cell.GetPiece()]
+	}
+	if m.strategy == PROMOTION {
+		victim += victimRanks[m.promotion]
+	}
+	return victim*8 - victimRanks[p.cells[from].GetPiece()]
+}
+
+// RankMoves returns the rank of each move, the higher to be searched the
+// sooner, using the heuristics of the engine
+// The hash move goes first, then captures and promotions, then killers, the
+// countermove to the previous move, the quiet moves by history, and last the
+// captures that lose material.
+func (e *Engine) RankMoves(g *Game, moves []Move, ply int, hashMove Move, previous Move) []int {
+	ranks := make([]int, len(moves))
+	o := &e.ordering
+	killers := o.killers[ply]
+	counterMove := Move{}
+	if previous != (Move{}) {
+		from, to := GetMoveSquares(previous)
+		counterMove = o.counterMoves[from][to]
+	}
+	for i, m := range moves {
+		switch {
+		case e.heuristics&HASHMOVE != 0 && m == hashMove:
+			ranks[i] = hashMoveRank
+		case !m.IsQuiet():
+			if e.heuristics&MVVLVA != 0 {
+				ranks[i] = captureRank + GetMVVLVA(g.position, m)
+			}
+			if e.heuristics&SEE != 0 && g.position.SEE(m) < 0 {
+				ranks[i] += losingRank - captureRank
+			}
+		case e.heuristics&KILLERS != 0 && m == killers[0]:
+			ranks[i] = killerRank + 1
+		case e.heuristics&KILLERS != 0 && m == killers[1]:
+			ranks[i] = killerRank
+		case e.heuristics&COUNTERMOVES != 0 && m == counterMove:
+			ranks[i] = counterMoveRank
+		case e.heuristics&HISTORY != 0:
+			from, to := GetMoveSquares(m)
+			ranks[i] = o.history[m.team][from][to]
+		}
+	}
+	return ranks
+}
+
+// PickMove swaps the move of the highest rank from index i on to index i
+// Picking one move at a time leaves the rest unsorted when an early one cuts
+// off, which is most of the time.
+func PickMove(moves []Move, ranks []int, i int) {
+	best := i
+	for j := i + 1; j < len(moves); j++ {
+		if ranks[j] > ranks[best] {
+			best = j
+		}
+	}
+	moves[i], moves[best] = moves[best], moves[i]
+	ranks[i], ranks[best] = ranks[best], ranks[i]
+}
+
+// UpdateOrdering learns from a quiet move that cut off at given depth and ply,
+// after the enemy played previous
+func (e *Engine) UpdateOrdering(m Move, depth int, ply int, previous Move) {
+	o := &e.ordering
+	if o.killers[ply][0] != m {
+		o.killers[ply][1] = o.killers[ply][0]
+		o.killers[ply][0] = m
+	}
+	if previous != (Move{}) {
+		from, to := GetMoveSquares(previous)
+		o.counterMoves[from][to] = m
+	}
+
+	from, to := GetMoveSquares(m)
+	o.history[m.team][from][to] += depth * depth
+	if o.history[m.team][from][to] >= maxHistory {
+		o.FadeHistory()
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rankFEN returns the legal moves of the game of given FEN, ranked by a new
+// engine after it learns from given killer, as if it cut off at ply 1
+func rankFEN(t *testing.T, fen string, hashSAN string, killerSAN string) (Game, []Move) {
+	game, err := NewGameFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(1, 0, 1, time.Now)
+	hashMove, err := game.ParseSAN(hashSAN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	killer, err := game.ParseSAN(killerSAN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.UpdateOrdering(killer, 3, 1, Move{})
+
+	moves := game.LegalMoves()
+	ranks := engine.RankMoves(&game, moves, 1, hashMove, Move{})
+	for i := range moves {
+		PickMove(moves, ranks, i)
+	}
+	return game, moves
+}
+
+func TestRankMoves(t *testing.T) {
+	// the white Knight can take the Rook or the pawn, the Queen the pawn too
+	game, moves := rankFEN(t, "4k3/8/2r5/3p4/1N6/3Q4/8/4K3 w - - 0 1", "Ke2", "Kf1")
+	expected := []string{"Ke2", "Nxc6", "Nxd5", "Qxd5", "Kf1"}
+	for i, san := range expected {
+		if got := game.GetSANWithoutSuffix(moves[i]); got != san {
+			t.Errorf("expected %s to rank #%d, got %s", san, i+1, got)
+		}
+	}
+}
+
+func TestMVVLVA(t *testing.T) {
+	game, err := NewGameFromFEN("4k3/8/8/3q4/4P3/2N5/8/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPawn, err := game.ParseSAN("exd5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byKnight, err := game.ParseSAN("Nxd5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetMVVLVA(game.position, byPawn) <= GetMVVLVA(game.position, byKnight) {
+		t.Error("expected the pawn to take the Queen before the Knight does")
+	}
+}
+
+func TestHeuristicsPrune(t *testing.T) {
+	total := func(nodes []int) int {
+		sum := 0
+		for _, n := range nodes {
+			sum += n
+		}
+		return sum
+	}
+	if ordered, unordered := total(Bench(2, allHeuristics)), total(Bench(2, 0)); ordered >= unordered {
+		t.Errorf("expected ordering to prune, searched %d nodes against %d", ordered, unordered)
+	}
+
+	output := &bytes.Buffer{}
+	if err := RunBench([]string{"1"}, output); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(output.String()), "\n"); len(lines) != len(heuristics)+2 {
+		t.Errorf("expected a row per heuristic, got:\n%s", output)
+	}
+	if err := RunBench([]string{"x"}, output); err == nil {
+		t.Error("expected an invalid depth not to run")
+	}
+}
+
+// BenchmarkSearch searches the bench positions, reporting the nodes searched
+func BenchmarkSearch(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		nodes := 0
+		for _, n := range Bench(defaultBenchDepth, allHeuristics) {
+			nodes += n
+		}
+		b.ReportMetric(float64(nodes), "nodes/op")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PerftDivision is the count of leaf nodes found under a single root move
+type PerftDivision struct {
+	move  Move
+	nodes int
+}
+
+// Perft counts the leaf nodes of the legal move tree of given depth
+// It is the standard way to validate a move generator, comparing the count
+// against published numbers for well-known positions.
+func (g Game) Perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	moves := g.LegalMoves()
+	if depth == 1 {
+		return len(moves)
+	}
+	nodes := 0
+	for _, m := range moves {
+		u := g.Make(m)
+		nodes += g.Perft(depth - 1)
+		g.Unmake(u)
+	}
+	return nodes
+}
+
+// Divide runs perft under each legal move of the game, sorted by command
+// A per-move breakdown narrows down which move a wrong count comes from.
+func (g Game) Divide(depth int) []PerftDivision {
+	divisions := []PerftDivision{}
+	if depth < 1 {
+		return divisions
+	}
+	for _, m := range g.LegalMoves() {
+		u := g.Make(m)
+		divisions = append(divisions, PerftDivision{
+			move:  m,
+			nodes: g.Perft(depth - 1),
+		})
+		g.Unmake(u)
+	}
+	sort.Slice(divisions, func(i, j int) bool {
+		return divisions[i].move.AsCommand() < divisions[j].move.AsCommand()
+	})
+	return divisions
+}
+
+// RunPerft runs the perft command, e.g. "chess perft 3 [fen]"
+// It prints every root move with its node count, followed by the total.
+// The position defaults to the initial one, and the FEN may be given
+// unquoted, as its fields are joined back together.
+func RunPerft(args []string, output io.Writer) error {
+	if len(args) < 1 {
+		return errors.New("usage: chess perft <depth> [fen]")
+	}
+	depth, err := strconv.Atoi(args[0])
+	if err != nil || depth < 1 {
+		return fmt.Errorf("invalid perft depth %q, must be a positive number", args[0])
+	}
+
+	game := NewGame()
+	if len(args) > 1 {
+		game, err = NewGameFromFEN(strings.Join(args[1:], " "))
+		if err != nil {
+			return err
+		}
+	}
+	total := 0
+	for _, division := range game.Divide(depth) {
+		fmt.Fprintf(output, "%s: %d\n", division.move.AsCommand(), division.nodes)
+		total += division.nodes
+	}
+	fmt.Fprintf(output, "\nNodes searched: %d\n", total)
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// checkPerft compares perft of every depth against expected node counts,
+// where expected[0] is the count of depth 1
+func checkPerft(t *testing.T, game Game, expected []int) {
+	for i, nodes := range expected {
+		depth := i + 1
+		if got := game.Perft(depth); got != nodes {
+			t.Errorf("perft(%d): expected %d nodes, got %d", depth, nodes, got)
+		}
+	}
+}
+
+func TestPerftInitial(t *testing.T) {
+	checkPerft(t, NewGame(), []int{20, 400, 8902, 197281})
+}
+
+func TestPerftKiwipete(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"● R", "   ", "   ", "   ", "● G", "   ", "   ", "● R"},
+		{"● P", "   ", "● P", "● P", "● Q", "● P", "● B", "   "},
+		{"● B", "● K", "   ", "   ", "● P", "● K", "● P", "   "},
+		{"   ", "   ", "   ", "○ P", "○ K", "   ", "   ", "   "},
+		{"   ", "● P", "   ", "   ", "○ P", "   ", "   ", "   "},
+		{"   ", "   ", "○ K", "   ", "   ", "○ Q", "   ", "● P"},
+		{"○ P", "○ P", "○ P", "○ B", "○ B", "○ P", "○ P", "○ P"},
+		{"○ R", "   ", "   ", "   ", "○ G", "   ", "   ", "○ R"},
+	}, WHITE)
+	checkPerft(t, game, []int{48, 2039, 97862})
+}
+
+func TestPerftPosition3(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "● P", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "● P", "   ", "   ", "   ", "   "},
+		{"○ G", "○ P", "   ", "   ", "   ", "   ", "   ", "● R"},
+		{"   ", "○ R", "   ", "   ", "   ", "● P", "   ", "● G"},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "○ P", "   ", "○ P", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+	}, WHITE)
+	checkPerft(t, game, []int{14, 191, 2812, 43238})
+}
+
+func TestPerftPosition4(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"● R", "   ", "   ", "   ", "● G", "   ", "   ", "● R"},
+		{"○ P", "● P", "● P", "● P", "   ", "● P", "● P", "● P"},
+		{"   ", "● B", "   ", "   ", "   ", "● K", "● B", "○ K"},
+		{"● K", "○ P", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ B", "○ B", "○ P", "   ", "○ P", "   ", "   ", "   "},
+		{"● Q", "   ", "   ", "   ", "   ", "○ K", "   ", "   "},
+		{"○ P", "● P", "   ", "○ P", "   ", "   ", "○ P", "○ P"},
+		{"○ R", "   ", "   ", "○ Q", "   ", "○ R", "○ G", "   "},
+	}, WHITE)
+	checkPerft(t, game, []int{6, 264, 9467, 422333})
+}
+
+func TestPerftPosition5(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"● R", "● K", "● B", "● Q", "   ", "● G", "   ", "● R"},
+		{"● P", "● P", "   ", "○ P", "● B", "● P", "● P", "● P"},
+		{"   ", "   ", "● P", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "○ B", "   ", "   ", "   ", "   ", "   "},
+		{"   ", "   ", "   ", "   ", "   ", "   ", "   ", "   "},
+		{"○ P", "○ P", "○ P", "   ", "○ K", "● K", "○ P", "○ P"},
+		{"○ R", "○ K", "○ B", "○ Q", "○ G", "   ", "   ", "○ R"},
+	}, WHITE)
+	checkPerft(t, game, []int{44, 1486, 62379})
+}
+
+func TestPerftPosition6(t *testing.T) {
+	game := NewGameFromBoard(Board{
+		{"● R", "   ", "   ", "   ", "   ", "● R", "● G", "   "},
+		{"   ", "● P", "● P", "   ", "● Q", "● P", "● P", "● P"},
+		{"● P", "   ", "● K", "● P", "   ", "● K", "   ", "   "},
+		{"   ", "   ", "● B", "   ", "● P", "   ", "○ B", "   "},
+		{"   ", "   ", "○ B", "   ", "○ P", "   ", "● B", "   "},
+		{"○ P", "   ", "○ K", "○ P", "   ", "○ K", "   ", "   "},
+		{"   ", "○ P", "○ P", "   ", "○ Q", "○ P", "○ P", "○ P"},
+		{"○ R", "   ", "   ", "   ", "   ", "○ R", "○ G", "   "},
+	}, WHITE)
+	checkPerft(t, game, []int{46, 2079, 89890})
+}
+
+func TestRunPerftDivide(t *testing.T) {
+	var output bytes.Buffer
+	if err := RunPerft([]string{"2"}, &output); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if lines[0] != "a7 a5: 20" {
+		t.Errorf("unexpected first division %q", lines[0])
+	}
+	if lines[len(lines)-1] != "Nodes searched: 400" {
+		t.Errorf("unexpected total %q", lines[len(lines)-1])
+	}
+}
+
+func TestRunPerftInvalidDepth(t *testing.T) {
+	var output bytes.Buffer
+	if err := RunPerft([]string{"zero"}, &output); err == nil {
+		t.Error("perft accepted an invalid depth")
+	}
+}
+
+func TestRunPerftFEN(t *testing.T) {
+	var output bytes.Buffer
+	args := []string{"1", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8", "w", "-", "-"}
+	if err := RunPerft(args, &output); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(output.String(), "Nodes searched: 14\n") {
+		t.Errorf("unexpected perft output %q", output.String())
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rosterTags are the tags every PGN game starts with, in this order
+var rosterTags = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// Record is the record of a game as kept in Portable Game Notation
+// It holds the tags, the position the game started from and every move
+// played since, in Standard Algebraic Notation.
+type Record struct {
+	tags        map[string]string
+	start       Game
+	moves       []Move
+	sans        []string
+	result      Result
+	termination string
+}
+
+// NewRecord returns an empty Record of a game starting from given position
+func NewRecord(g Game) Record {
+	r := Record{
+		tags: map[string]string{
+			"Event": "Casual game",
+			"Site":  "?",
+			"Date":  time.Now().Format("2006.01.02"),
+			"Round": "-",
+			"White": "?",
+			"Black": "?",
+		},
+		start:  g,
+		result: UNFINISHED,
+	}
+
+	// a game that does not start from the initial position needs it stated
+	if g.AsFEN() != InitialFEN {
+		r.tags["SetUp"] = "1"
+		r.tags["FEN"] = g.AsFEN()
+	}
+	return r
+}
+
+// Add records a move, played on given game before it is executed
+func (r *Record) Add(g Game, m Move) {
+	r.moves = append(r.moves, m)
+	r.sans = append(r.sans, g.GetSAN(m))
+}
+
+// GetGame returns the game as it stands after every recorded move
+func (r Record) GetGame() Game {
+	g, _ := r.GetGameWithUndos()
+	return g
+}
+
+// GetGameWithUndos returns the game as it stands after every recorded move,
+// along with the Undo of each move to take them back in reverse order
+func (r Record) GetGameWithUndos() (Game, []Undo) {
+	g := r.start
+	undos := []Undo{}
+	for _, m := range r.moves {
+		undos = append(undos, g.Make(m))
+	}
+	return g, undos
+}
+
+// TakeBack forgets the last recorded move and returns it in SAN
+func (r *Record) TakeBack() string {
+	if len(r.moves) == 0 {
+		return ""
+	}
+	san := r.sans[len(r.sans)-1]
+	r.moves = r.moves[:len(r.moves)-1]
+	r.sans = r.sans[:len(r.sans)-1]
+	return san
+}
+
+// End records the result of the game and, optionally, how it ended
+// e.g. "white resigns"
+func (r *Record) End(result Result, termination string) {
+	r.result = result
+	r.termination = termination
+}
+
+// SetTag sets a tag of the record, e.g. "White" to the name of a player
+func (r *Record) SetTag(name string, value string) {
+	r.tags[name] = value
+}
+
+// GetPGNResultName returns the result as a PGN game termination marker
+func GetPGNResultName(result Result) string {
+	if result == DRAW {
+		return "1/2-1/2"
+	}
+	return GetResultName(result)
+}
+
+// AsPGN returns the record as a PGN game
+func (r Record) AsPGN() string {
+	var pgn strings.Builder
+
+	// tags, the Seven Tag Roster first and then the rest alphabetically
+	tags := map[string]string{}
+	for name, value := range r.tags {
+		tags[name] = value
+	}
+	tags["Result"] = GetPGNResultName(r.result)
+	for _, name := range rosterTags {
+		pgn.WriteString(GetPGNTag(name, tags[name]))
+		delete(tags, name)
+	}
+	others := []string{}
+	for name := range tags {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		pgn.WriteString(GetPGNTag(name, tags[name]))
+	}
+	pgn.WriteString("\n")
+
+	// movetext, numbered from where the game started
+	tokens := strings.Fields(r.AsMoveList())
+	if r.termination != "" {
+		tokens = append(tokens, "{"+r.termination+"}")
+	}
+	tokens = append(tokens, GetPGNResultName(r.result))
+
+	// lines of movetext are kept under 80 characters
+	line := ""
+	for _, token := range tokens {
+		if line != "" && len(line)+1+len(token) > 79 {
+			pgn.WriteString(line + "\n")
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += token
+	}
+	pgn.WriteString(line + "\n")
+
+	return pgn.String()
+}
+
+// AsMoveList returns the recorded moves in SAN, numbered from where the game
+// started, e.g. "1. e4 e5 2. Nf3"
+func (r Record) AsMoveList() string {
+	tokens := []string{}
+	moveNumber := r.start.fullmoves
+	turn := r.start.turn
+	for i, san := range r.sans {
+		if turn == WHITE {
+			tokens = append(tokens, strconv.Itoa(moveNumber)+".")
+		} else if i == 0 {
+			tokens = append(tokens, strconv.Itoa(moveNumber)+"...")
+		}
+		tokens = append(tokens, san)
+		if turn == BLACK {
+			moveNumber++
+			turn = WHITE
+		} else {
+			turn = BLACK
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// GetPGNTag returns a PGN tag pair line, escaping its value
+func GetPGNTag(name string, value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return fmt.Sprintf("[%s \"%s\"]\n", name, value)
+}
+
+// Save writes the record as a PGN file
+func (r Record) Save(path string) error {
+	return ioutil.WriteFile(path, []byte(r.AsPGN()), 0644)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// playRecord plays commands on game and returns their record
+func playRecord(t *testing.T, game Game, commands []string) (Game, Record) {
+	record := NewRecord(game)
+	record.SetTag("Date", "2020.05.01")
+	for _, command := range commands {
+		move, isValid, messages, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid: %v", command, messages)
+		}
+		record.Add(game, move)
+		game.Execute(move)
+	}
+	return game, record
+}
+
+func TestPGNCheckmate(t *testing.T) {
+	game, record := playRecord(t, NewGame(), []string{"f7 f6", "e2 e4", "g7 g5", "d1 h5"})
+	record.End(game.GetResult(), "")
+
+	expected := `[Event "Casual game"]
+[Site "?"]
+[Date "2020.05.01"]
+[Round "-"]
+[White "?"]
+[Black "?"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`
+	if record.AsPGN() != expected {
+		t.Errorf("unexpected PGN:\n%s", record.AsPGN())
+	}
+}
+
+func TestPGNFromPosition(t *testing.T) {
+	game, err := NewGameFromFEN("4k3/8/8/8/8/8/1p6/R3K3 b Q - 0 40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, record := playRecord(t, game, []string{"b7 b8 Q"})
+	record.End(GetWinResult(BLACK), "white resigns")
+
+	expected := `[Event "Casual game"]
+[Site "?"]
+[Date "2020.05.01"]
+[Round "-"]
+[White "?"]
+[Black "?"]
+[Result "0-1"]
+[FEN "4k3/8/8/8/8/8/1p6/R3K3 b Q - 0 40"]
+[SetUp "1"]
+
+40... b1=Q+ {white resigns} 0-1
+`
+	if record.AsPGN() != expected {
+		t.Errorf("unexpected PGN:\n%s", record.AsPGN())
+	}
+}
+
+func TestPGNDraw(t *testing.T) {
+	game, record := playRecord(t, NewGame(), []string{})
+	record.End(DRAW, "")
+	if GetPGNResultName(record.result) != "1/2-1/2" {
+		t.Error("draw is not written as 1/2-1/2")
+	}
+	if game.turn != WHITE {
+		t.Error("no move should have been played")
+	}
+}
+
+func TestPGNLineLength(t *testing.T) {
+	commands := []string{}
+	for i := 0; i < 10; i++ {
+		commands = append(commands, "g8 f6", "g1 f3", "f6 g8", "f3 g1")
+	}
+	_, record := playRecord(t, NewGame(), commands)
+	for _, line := range strings.Split(record.AsPGN(), "\n") {
+		if len(line) > 79 {
+			t.Errorf("PGN line longer than 79 characters: %q", line)
+		}
+	}
+}
+
+func TestPGNSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, record := playRecord(t, NewGame(), []string{"e7 e5"})
+	path := filepath.Join(dir, "game.pgn")
+	if err := record.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != record.AsPGN() {
+		t.Error("saved PGN differs from record")
+	}
+}
+
+func TestRecordMoveList(t *testing.T) {
+	_, record := playRecord(t, NewGame(), []string{"e4", "e5", "Nf3"})
+	if record.AsMoveList() != "1. e4 e5 2. Nf3" {
+		t.Errorf("unexpected move list %s", record.AsMoveList())
+	}
+}
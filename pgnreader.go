@@ -0,0 +1,367 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pgnSuffixes are the move suffix annotations and the NAGs they stand for
+var pgnSuffixes = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// pgnResults are the game termination markers of PGN movetext
+var pgnResults = map[string]Result{
+	"1-0":     WHITEWINS,
+	"0-1":     BLACKWINS,
+	"1/2-1/2": DRAW,
+	"*":       UNFINISHED,
+}
+
+// PGNGame is a game as read from a PGN file, before it is replayed
+type PGNGame struct {
+	tags     map[string]string
+	comments []string
+	moves    []PGNMove
+	result   Result
+}
+
+// PGNMove is a move of a PGN game as written, along with its annotations
+// Variations are alternatives to this move, each a line of moves of its own.
+type PGNMove struct {
+	san        string
+	nags       []int
+	comments   []string
+	variations [][]PGNMove
+}
+
+// IllegalMoveError is returned when a PGN game has a move the rules reject
+type IllegalMoveError struct {
+	ply    int
+	san    string
+	reason string
+}
+
+func (e IllegalMoveError) Error() string {
+	return fmt.Sprintf("illegal move %s at ply %d: %s", e.san, e.ply, e.reason)
+}
+
+// LoadPGN reads every game of a PGN file
+func LoadPGN(path string) ([]PGNGame, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePGN(string(content))
+}
+
+// ParsePGN reads every game of a PGN text
+// Comments, NAGs and move suffixes are kept with the move they follow, and
+// recursive variations are kept with the move they are an alternative to.
+func ParsePGN(text string) ([]PGNGame, error) {
+	games := []PGNGame{}
+	game := NewPGNGame()
+	started := false
+
+	// lines stack up as variations open, the main line being at the bottom
+	lines := [][]PGNMove{{}}
+
+	// lastMove returns the move annotations apply to, if any
+	lastMove := func() *PGNMove {
+		line := lines[len(lines)-1]
+		if len(line) == 0 {
+			return nil
+		}
+		return &line[len(line)-1]
+	}
+
+	// finish closes the game being read and starts a new one
+	finish := func() {
+		game.moves = lines[0]
+		games = append(games, game)
+		game = NewPGNGame()
+		lines = [][]PGNMove{{}}
+		started = false
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		// lines starting with a percent sign are escaped from parsing
+		if r == '%' && (i == 0 || runes[i-1] == '\n') {
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			continue
+		}
+
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		switch r {
+		case '[':
+			// a tag pair after movetext means a game without result marker ended
+			if started {
+				finish()
+			}
+			end := i + 1
+			inString := false
+			for end < len(runes) && (inString || runes[end] != ']') {
+				if runes[end] == '\\' && inString {
+					end++
+				} else if runes[end] == '"' {
+					inString = !inString
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("game %d: unterminated tag pair", len(games)+1)
+			}
+			name, value, err := ParsePGNTag(string(runes[i+1 : end]))
+			if err != nil {
+				return nil, fmt.Errorf("game %d: %v", len(games)+1, err)
+			}
+			game.tags[name] = value
+			i = end
+		case '{':
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("game %d: unterminated comment", len(games)+1)
+			}
+			comment := strings.Join(strings.Fields(string(runes[i+1:end])), " ")
+			if m := lastMove(); m != nil {
+				m.comments = append(m.comments, comment)
+			} else if len(lines) == 1 {
+				game.comments = append(game.comments, comment)
+			}
+			i = end
+		case ';':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			comment := strings.TrimSpace(string(runes[i+1 : end]))
+			if m := lastMove(); m != nil {
+				m.comments = append(m.comments, comment)
+			} else if len(lines) == 1 {
+				game.comments = append(game.comments, comment)
+			}
+			i = end
+		case '(':
+			if lastMove() == nil {
+				return nil, fmt.Errorf("game %d: variation before any move", len(games)+1)
+			}
+			lines = append(lines, []PGNMove{})
+		case ')':
+			if len(lines) == 1 {
+				return nil, fmt.Errorf("game %d: unbalanced variation parenthesis", len(games)+1)
+			}
+			variation := lines[len(lines)-1]
+			lines = lines[:len(lines)-1]
+			m := lastMove()
+			m.variations = append(m.variations, variation)
+		case ']', '}':
+			return nil, fmt.Errorf("game %d: unexpected %q", len(games)+1, r)
+		default:
+			// any other token runs until whitespace or a delimiter
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("[]{}();", runes[end]) {
+				end++
+			}
+			token := string(runes[i:end])
+			i = end - 1
+			started = true
+
+			if result, ok := pgnResults[token]; ok {
+				if len(lines) > 1 {
+					return nil, fmt.Errorf("game %d: result inside a variation", len(games)+1)
+				}
+				game.result = result
+				finish()
+				continue
+			}
+
+			if strings.HasPrefix(token, "$") {
+				nag, err := strconv.Atoi(token[1:])
+				if err != nil {
+					return nil, fmt.Errorf("game %d: invalid NAG %q", len(games)+1, token)
+				}
+				if m := lastMove(); m != nil {
+					m.nags = append(m.nags, nag)
+				}
+				continue
+			}
+
+			if nag, ok := pgnSuffixes[token]; ok {
+				if m := lastMove(); m != nil {
+					m.nags = append(m.nags, nag)
+				}
+				continue
+			}
+
+			// move numbers, e.g. "12." or "12...", may be stuck to the move,
+			// unlike castling with zeros, e.g. "0-0-0+"
+			castling := strings.TrimRight(token, "+#!?")
+			isCastling := castling == "0-0" || castling == "0-0-0"
+			if unicode.IsDigit([]rune(token)[0]) && !isCastling {
+				dot := strings.LastIndex(token, ".")
+				if dot < 0 {
+					return nil, fmt.Errorf("game %d: unexpected token %q", len(games)+1, token)
+				}
+				token = token[dot+1:]
+				if token == "" {
+					continue
+				}
+			}
+
+			// move suffixes, e.g. "Nf3!?", become NAGs of the move
+			san := strings.TrimRight(token, "!?")
+			m := PGNMove{san: san}
+			if suffix := token[len(san):]; suffix != "" {
+				nag, ok := pgnSuffixes[suffix]
+				if !ok {
+					return nil, fmt.Errorf("game %d: invalid move suffix %q", len(games)+1, token)
+				}
+				m.nags = append(m.nags, nag)
+			}
+			lines[len(lines)-1] = append(lines[len(lines)-1], m)
+		}
+	}
+
+	if len(lines) > 1 {
+		return nil, fmt.Errorf("game %d: unterminated variation", len(games)+1)
+	}
+	if started || len(lines[0]) > 0 {
+		finish()
+	}
+	return games, nil
+}
+
+// NewPGNGame returns an empty PGNGame
+func NewPGNGame() PGNGame {
+	return PGNGame{
+		tags:   map[string]string{},
+		result: UNFINISHED,
+	}
+}
+
+// ParsePGNTag returns name and value of a tag pair without its brackets
+// e.g. `Event "F/S Return Match"`, where values may escape quotes and
+// backslashes with a backslash.
+func ParsePGNTag(pair string) (string, string, error) {
+	pair = strings.TrimSpace(pair)
+	space := strings.IndexFunc(pair, unicode.IsSpace)
+	if space < 0 {
+		return "", "", fmt.Errorf("invalid tag pair %q", pair)
+	}
+	name := pair[:space]
+	quoted := strings.TrimSpace(pair[space:])
+	if len(quoted) < 2 || quoted[0] != '"' || quoted[len(quoted)-1] != '"' {
+		return "", "", fmt.Errorf("invalid tag value in %q", pair)
+	}
+
+	value := ""
+	escaped := false
+	for _, r := range quoted[1 : len(quoted)-1] {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		value += string(r)
+		escaped = false
+	}
+	return name, value, nil
+}
+
+// Replay plays the main line of the game through the rules engine
+// It returns the record of the game, or an IllegalMoveError on the first
+// move the rules reject.
+func (pg PGNGame) Replay() (Record, error) {
+	game := NewGame()
+	if fen, ok := pg.tags["FEN"]; ok {
+		var err error
+		game, err = NewGameFromFEN(fen)
+		if err != nil {
+			return Record{}, err
+		}
+	}
+
+	record := NewRecord(game)
+	for name, value := range pg.tags {
+		record.SetTag(name, value)
+	}
+	for i, pgnMove := range pg.moves {
+		ply := i + 1
+		m, err := game.ParseSAN(pgnMove.san)
+		if err != nil {
+			return record, IllegalMoveError{ply: ply, san: pgnMove.san, reason: err.Error()}
+		}
+		move, isValid, messages, _ := game.NewMove(m.AsCommand())
+		if !isValid {
+			return record, IllegalMoveError{ply: ply, san: pgnMove.san, reason: strings.Join(messages, "; ")}
+		}
+		record.Add(game, move)
+		game.Execute(move)
+	}
+	record.End(pg.result, "")
+	return record, nil
+}
+
+// LoadRecord returns the record of the first game of a PGN file, replayed
+// so that it can be resumed
+func LoadRecord(path string) (Record, error) {
+	games, err := LoadPGN(path)
+	if err != nil {
+		return Record{}, err
+	}
+	if len(games) == 0 {
+		return Record{}, fmt.Errorf("no game found in %s", path)
+	}
+	record, err := games[0].Replay()
+	if err != nil {
+		return Record{}, err
+	}
+	record.End(UNFINISHED, "")
+	return record, nil
+}
+
+// RunReplay runs the replay command, e.g. "chess replay games.pgn"
+// It replays every game of the file and reports, per game, either how many
+// moves were played or the first illegal one.
+func RunReplay(args []string, output io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("usage: chess replay <file>")
+	}
+	games, err := LoadPGN(args[0])
+	if err != nil {
+		return err
+	}
+
+	illegal := 0
+	for i, pg := range games {
+		name := fmt.Sprintf("%s - %s", pg.tags["White"], pg.tags["Black"])
+		if _, err := pg.Replay(); err != nil {
+			fmt.Fprintf(output, "Game %d (%s): %v\n", i+1, name, err)
+			illegal++
+			continue
+		}
+		fmt.Fprintf(output, "Game %d (%s): %d plies OK\n", i+1, name, len(pg.moves))
+	}
+	fmt.Fprintf(output, "\nGames replayed: %d, with illegal moves: %d\n", len(games), illegal)
+	return nil
+}
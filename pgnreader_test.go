@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fischerSpassky = `[Event "F/S Return Match"]
+[Site "Belgrade, Serbia JUG"]
+[Date "1992.11.04"]
+[Round "29"]
+[White "Fischer, Robert J."]
+[Black "Spassky, Boris V."]
+[Result "1/2-1/2"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.}
+4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7
+11. c4 c6 12. cxb5 axb5 13. Nc3 Bb7 14. Bg5 b4 15. Nb1 h6 16. Bh4 c5 17. dxe5
+Nxe4 18. Bxe7 Qxe7 19. exd6 Qf6 20. Nbd2 Nxd6 21. Nc4 Nxc4 22. Bxc4 Nb6
+23. Ne5 Rae8 24. Bxf7+ Rxf7 25. Nxf7 Rxe1+ 26. Qxe1 Kxf7 27. Qe3 Qg5 28. Qxg5
+hxg5 29. b3 Ke6 30. a3 Kd6 31. axb4 cxb4 32. Ra5 Nd5 33. f3 Bc8 34. Kf2 Bf5
+35. Ra7 g6 36. Ra6+ Kc5 37. Ke1 Nf4 38. g3 Nxh3 39. Kd2 Kb5 40. Rd6 Kc5 41. Ra6
+Nf2 42. g4 Bd3 43. Re6 1/2-1/2
+`
+
+func TestParsePGNAnnotations(t *testing.T) {
+	text := `% a line escaped from parsing [Event "Ignored"]
+[Event "Club \"Open\" \\ 2020"]
+[White "A"]
+
+{Opening comment} 1.e4 $1 e5!? (1... c5 2. Nf3 (2. c3) d6; rest of line
+) 2. Nf3 Nc6 * 
+
+[Event "Second"]
+
+1. d4 d5 0-1
+`
+	games, err := ParsePGN(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("expected 2 games, got %d", len(games))
+	}
+
+	first := games[0]
+	if first.tags["Event"] != `Club "Open" \ 2020` {
+		t.Errorf("tag escapes not handled: %q", first.tags["Event"])
+	}
+	if len(first.comments) != 1 || first.comments[0] != "Opening comment" {
+		t.Errorf("unexpected game comments %v", first.comments)
+	}
+	sans := []string{}
+	for _, m := range first.moves {
+		sans = append(sans, m.san)
+	}
+	if strings.Join(sans, " ") != "e4 e5 Nf3 Nc6" {
+		t.Errorf("unexpected main line %v", sans)
+	}
+	if len(first.moves[0].nags) != 1 || first.moves[0].nags[0] != 1 {
+		t.Errorf("NAG not kept: %v", first.moves[0].nags)
+	}
+	if len(first.moves[1].nags) != 1 || first.moves[1].nags[0] != 5 {
+		t.Errorf("move suffix not kept as NAG: %v", first.moves[1].nags)
+	}
+
+	// variation of 1... e5, with a variation of its own
+	variations := first.moves[1].variations
+	if len(variations) != 1 || len(variations[0]) != 3 {
+		t.Fatalf("unexpected variations %v", variations)
+	}
+	if variations[0][1].san != "Nf3" || len(variations[0][1].variations) != 1 {
+		t.Errorf("nested variation not kept: %v", variations[0])
+	}
+	if len(variations[0][2].comments) != 1 || variations[0][2].comments[0] != "rest of line" {
+		t.Errorf("line comment not kept: %v", variations[0][2].comments)
+	}
+	if first.result != UNFINISHED || games[1].result != BLACKWINS {
+		t.Error("game results not read")
+	}
+}
+
+func TestParsePGNInvalid(t *testing.T) {
+	texts := []string{
+		`[Event "Unterminated`,
+		"1. e4 {unterminated",
+		"1. e4 (1. d4",
+		"1. e4 e5)",
+		"(1. e4)",
+		"1. e4 $x",
+		"1. e4 } e5 *",
+		`[Event "x"]]`,
+	}
+	for _, text := range texts {
+		if _, err := ParsePGN(text); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
+
+func TestReplayPGN(t *testing.T) {
+	games, err := ParsePGN(fischerSpassky)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := games[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.moves) != 85 {
+		t.Errorf("expected 85 plies, got %d", len(record.moves))
+	}
+	if record.GetGame().AsFEN() != "8/8/4R1p1/2k3p1/1p4P1/1P1b1P2/3K1n2/8 b - - 2 43" {
+		t.Errorf("unexpected final position %s", record.GetGame().AsFEN())
+	}
+
+	// exported again, the game reads the same
+	again, err := ParsePGN(record.AsPGN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range again[0].moves {
+		if m.san != games[0].moves[i].san {
+			t.Errorf("ply %d exported as %s instead of %s", i+1, m.san, games[0].moves[i].san)
+		}
+	}
+}
+
+func TestReplayPGNIllegalMove(t *testing.T) {
+	games, err := ParsePGN("1. e4 e5 2. Ke3 Nc6 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = games[0].Replay()
+	illegal, ok := err.(IllegalMoveError)
+	if !ok {
+		t.Fatalf("expected illegal move error, got %v", err)
+	}
+	if illegal.ply != 3 || illegal.san != "Ke3" {
+		t.Errorf("unexpected illegal move %v", illegal)
+	}
+}
+
+func TestReplayPGNFromPosition(t *testing.T) {
+	text := `[FEN "4k3/8/8/8/8/8/1p6/R3K3 b Q - 0 40"]
+[SetUp "1"]
+
+40... b1=Q+ 41. Kd2 Qxa1 *`
+	games, err := ParsePGN(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := games[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.GetGame().AsFEN() != "4k3/8/8/8/8/8/3K4/q7 w - - 0 42" {
+		t.Errorf("unexpected final position %s", record.GetGame().AsFEN())
+	}
+}
+
+func TestReplayPGNZeroCastling(t *testing.T) {
+	text := "1. e4 e5 2. Nf3 Nc6 3. Bc4 Nf6 4.0-0 Bc5 5. d3 d6 6. Be3 Bg4 7. Nbd2 Qd7 8. c3 0-0-0 *"
+	games, err := ParsePGN(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := games[0].Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.GetGame().AsFEN() != "2kr3r/pppq1ppp/2np1n2/2b1p3/2B1P1b1/2PPBN2/PP1N1PPP/R2Q1RK1 w - - 1 9" {
+		t.Errorf("unexpected final position %s", record.GetGame().AsFEN())
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "games.pgn")
+	content := fischerSpassky + "\n[White \"X\"]\n[Black \"Y\"]\n\n1. e4 e5 2. Ke3 *\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	if err := RunReplay([]string{path}, &output); err != nil {
+		t.Fatal(err)
+	}
+	expected := `Game 1 (Fischer, Robert J. - Spassky, Boris V.): 85 plies OK
+Game 2 (X - Y): illegal move Ke3 at ply 3: invalid; no white King can move to e3
+
+Games replayed: 2, with illegal moves: 1
+`
+	if output.String() != expected {
+		t.Errorf("unexpected replay output:\n%s", output.String())
+	}
+}
@@ -1,5 +1,9 @@
 package main
 
+import (
+	"strings"
+)
+
 // Piece defines all chess pieces of the board
 type Piece int
 
@@ -32,6 +36,32 @@ func GetPiece(pieceNotation rune) Piece {
 	return pieces[pieceNotation]
 }
 
+// GetPieceFromAlgebraic returns the Piece given its algebraic notation letter
+// e.g. "N" -> KNIGHT, while pawns have no letter
+func GetPieceFromAlgebraic(pieceNotation string) Piece {
+	pieces := map[string]Piece{
+		"R": ROOK,
+		"N": KNIGHT,
+		"B": BISHOP,
+		"Q": QUEEN,
+		"K": KING,
+	}
+	return pieces[pieceNotation]
+}
+
+// GetPromotionPiece returns the Piece a pawn can be promoted to given its notation
+// e.g. "Q" or "q" -> QUEEN, and whether the notation is a valid promotion
+func GetPromotionPiece(pieceNotation string) (Piece, bool) {
+	pieces := map[string]Piece{
+		"Q": QUEEN,
+		"R": ROOK,
+		"K": KNIGHT,
+		"B": BISHOP,
+	}
+	piece, ok := pieces[strings.ToUpper(pieceNotation)]
+	return piece, ok
+}
+
 // GetPieceName returns given piece in given format
 func GetPieceName(piece Piece, format Format) string {
 	if format == SYMBOL {
@@ -54,6 +84,16 @@ func GetPieceName(piece Piece, format Format) string {
 			KING:   "King",
 		}
 		return verbosePieceNames[piece]
+	} else if format == ALGEBRAIC {
+		algebraicPieceNames := map[Piece]string{
+			PAWN:   "",
+			ROOK:   "R",
+			KNIGHT: "N",
+			BISHOP: "B",
+			QUEEN:  "Q",
+			KING:   "K",
+		}
+		return algebraicPieceNames[piece]
 	} else if format == UPPER {
 		upperPieceNames := map[Piece]string{
 			PAWN:   "PAWN",
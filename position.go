@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Cell is the content of a square packed in a byte
+// The low three bits hold the piece plus one and the fourth bit the team, so
+// that an empty square is the zero Cell.
+type Cell uint8
+
+// EMPTY is the Cell of a square with no piece on it
+const EMPTY Cell = 0
+
+// NewCell returns the Cell of given piece of given team
+func NewCell(team Team, piece Piece) Cell {
+	return Cell(team)<<3 | Cell(piece+1)
+}
+
+// GetCellFromSymbol returns the Cell of a board square as rendered, e.g. "○ P"
+func GetCellFromSymbol(symbol string) Cell {
+	runes := []rune(symbol)
+	if len(runes) != 3 || runes[2] == ' ' {
+		return EMPTY
+	}
+	team := WHITE
+	if runes[0] == '●' {
+		team = BLACK
+	}
+	return NewCell(team, GetPiece(runes[2]))
+}
+
+// IsEmpty returns whether no piece stands on the square
+func (c Cell) IsEmpty() bool {
+	return c == EMPTY
+}
+
+// GetTeam returns the team of the piece on the square, NEITHER when empty
+func (c Cell) GetTeam() Team {
+	if c == EMPTY {
+		return NEITHER
+	}
+	return Team(c >> 3)
+}
+
+// GetPiece returns the piece on the square, PAWN when empty
+func (c Cell) GetPiece() Piece {
+	if c == EMPTY {
+		return PAWN
+	}
+	return Piece(c&7) - 1
+}
+
+// AsSquare returns the Cell as a Square struct
+func (c Cell) AsSquare() Square {
+	return Square{
+		team:    c.GetTeam(),
+		piece:   c.GetPiece(),
+		isEmpty: c.IsEmpty(),
+	}
+}
+
+// AsSymbol returns the Cell the way the board renders it, e.g. "○ P"
+func (c Cell) AsSymbol() string {
+	if c == EMPTY {
+		return "   "
+	}
+	return GetTeamName(c.GetTeam(), SYMBOL) + " " + GetPieceName(c.GetPiece(), SYMBOL)
+}
+
+// Position is the typed state of the board, one Cell per square
+// Squares are stored row by row, so a square is found at row*8 + col. Board is
+// kept as its rendering layer. The same pieces are also kept as bitboards,
+// per team and piece, to compute attacks with a few mask operations.
+type Position struct {
+	cells  [64]Cell
+	pieces [2][6]Bitboard
+	teams  [2]Bitboard
+	// hash is the Zobrist hash of the pieces, see Game.GetHash
+	hash uint64
+}
+
+// NewPositionFromBoard returns the Position of the pieces on given board
+func NewPositionFromBoard(b Board) Position {
+	p := Position{}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			p.SetCell(i, j, GetCellFromSymbol(b[i][j]))
+		}
+	}
+	return p
+}
+
+// AsBoard returns the Position as a Board, ready to be rendered
+func (p Position) AsBoard() Board {
+	b := Board{}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			b[i][j] = p.cells[i*8+j].AsSymbol()
+		}
+	}
+	return b
+}
+
+// Render prints the position in stdout, the same way a Board does
+func (p Position) Render() {
+	b := p.AsBoard()
+	b.Render()
+}
+
+// GetCell returns the Cell of the square on given row and col
+func (p Position) GetCell(row int, col int) Cell {
+	return p.cells[row*8+col]
+}
+
+// SetCell puts a Cell on the square on given row and col, replacing whatever
+// stood there
+func (p *Position) SetCell(row int, col int, c Cell) {
+	square := row*8 + col
+	bit := Bitboard(1) << uint(square)
+	if old := p.cells[square]; old != EMPTY {
+		p.pieces[old.GetTeam()][old.GetPiece()] &^= bit
+		p.teams[old.GetTeam()] &^= bit
+	}
+	if c != EMPTY {
+		p.pieces[c.GetTeam()][c.GetPiece()] |= bit
+		p.teams[c.GetTeam()] |= bit
+	}
+	p.hash ^= GetCellKey(p.cells[square], square) ^ GetCellKey(c, square)
+	p.cells[square] = c
+}
+
+// GetOccupied returns the squares any piece stands on
+func (p Position) GetOccupied() Bitboard {
+	return p.teams[WHITE] | p.teams[BLACK]
+}
+
+// GetAttackers returns the squares of the pieces of attacker team that attack
+// given square, with occupied being the squares that block sliding pieces
+func (p Position) GetAttackers(square int, attacker Team, occupied Bitboard) Bitboard {
+	defender := WHITE
+	if attacker == WHITE {
+		defender = BLACK
+	}
+	pieces := &p.pieces[attacker]
+	attackers := pawnAttacks[defender][square] & pieces[PAWN]
+	attackers |= knightAttacks[square] & pieces[KNIGHT]
+	attackers |= kingAttacks[square] & pieces[KING]
+	attackers |= GetBishopAttacks(square, occupied) & (pieces[BISHOP] | pieces[QUEEN])
+	attackers |= GetRookAttacks(square, occupied) & (pieces[ROOK] | pieces[QUEEN])
+	return attackers
+}
+
+// IsAttacked returns whether any piece of attacker team attacks given square
+func (p Position) IsAttacked(square int, attacker Team) bool {
+	return p.GetAttackers(square, attacker, p.GetOccupied()) != 0
+}
+
+// GetSquare returns the part piece that is to be moved, either BEFORE or AFTER
+func (p Position) GetSquare(m Move, part Part) Square {
+	location := m.GetLocation(part)
+	return p.cells[location.row*8+location.col].AsSquare()
+}
+
+// ParseSquare returns square based on indexes
+func (p Position) ParseSquare(row int, col int) Square {
+	return p.cells[row*8+col].AsSquare()
+}
+
+// FindKing returns the square of the King of given team
+func (p Position) FindKing(team Team) Location {
+	king := p.pieces[team][KING]
+	if king == 0 {
+		return Location{}
+	}
+	square := king.First()
+	return Location{
+		row: square / 8,
+		col: square % 8,
+	}
+}
+
+// Execute applies a move to the position and returns the captured piece
+// Essentially, it is the move of a piece on the board.
+func (p *Position) Execute(m Move) Cell {
+	oldLocation := m.GetLocation(BEFORE)
+	newLocation := m.GetLocation(AFTER)
+
+	if !IsLocationValid(newLocation.row, newLocation.col) {
+		errorMsg := fmt.Sprintf("move destination location (%d:%d) is invalid", newLocation.row, newLocation.col)
+		panic(errorMsg)
+	}
+	if !IsLocationValid(oldLocation.row, oldLocation.col) {
+		errorMsg := fmt.Sprintf("move origin location (%d:%d) is invalid", oldLocation.row, oldLocation.col)
+		panic(errorMsg)
+	}
+	piece := p.GetCell(oldLocation.row, oldLocation.col).GetPiece()
+	if m.strategy == PROMOTION {
+		piece = m.promotion
+	}
+	captured := p.GetCell(newLocation.row, newLocation.col)
+	p.SetCell(newLocation.row, newLocation.col, NewCell(m.team, piece))
+	p.SetCell(oldLocation.row, oldLocation.col, EMPTY)
+
+	// en passant captures the pawn beside the origin, not on the destination
+	if m.strategy == ENPASSANT {
+		capturedLocation := m.GetEnPassantCapture()
+		captured = p.GetCell(capturedLocation.row, capturedLocation.col)
+		p.SetCell(capturedLocation.row, capturedLocation.col, EMPTY)
+	}
+
+	// castling moves the Rook too, to the other side of the King
+	if m.strategy == CASTLING {
+		rookOldCol := GetRookColumn(m.GetSide())
+		rookNewCol := 5
+		if m.GetSide() == QUEENSIDE {
+			rookNewCol = 3
+		}
+		p.SetCell(oldLocation.row, rookNewCol, p.GetCell(oldLocation.row, rookOldCol))
+		p.SetCell(oldLocation.row, rookOldCol, EMPTY)
+	}
+	return captured
+}
+
+// Unexecute takes back a move executed on the position, putting back the
+// piece it captured
+func (p *Position) Unexecute(m Move, captured Cell) {
+	oldLocation := m.GetLocation(BEFORE)
+	newLocation := m.GetLocation(AFTER)
+	piece := p.GetCell(newLocation.row, newLocation.col).GetPiece()
+	if m.strategy == PROMOTION {
+		piece = PAWN
+	}
+	p.SetCell(oldLocation.row, oldLocation.col, NewCell(m.team, piece))
+	p.SetCell(newLocation.row, newLocation.col, captured)
+
+	// the pawn captured en passant goes back beside the origin
+	if m.strategy == ENPASSANT {
+		capturedLocation := m.GetEnPassantCapture()
+		p.SetCell(newLocation.row, newLocation.col, EMPTY)
+		p.SetCell(capturedLocation.row, capturedLocation.col, captured)
+	}
+
+	// the Rook goes back to its corner
+	if m.strategy == CASTLING {
+		rookOldCol := GetRookColumn(m.GetSide())
+		rookNewCol := 5
+		if m.GetSide() == QUEENSIDE {
+			rookNewCol = 3
+		}
+		p.SetCell(oldLocation.row, rookOldCol, p.GetCell(oldLocation.row, rookNewCol))
+		p.SetCell(oldLocation.row, rookNewCol, EMPTY)
+	}
+}
+
+// IsCheckedAfter returns true if possiblyCheckedTeam is in check, after given
+// move has been executed
+// The move is unexecuted before returning, so the position is left as it was.
+func (p *Position) IsCheckedAfter(m Move, possiblyCheckedTeam Team) bool {
+	captured := p.Execute(m)
+	isChecked := p.IsChecked(possiblyCheckedTeam)
+	p.Unexecute(m, captured)
+	return isChecked
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCell(t *testing.T) {
+	for _, team := range []Team{WHITE, BLACK} {
+		for _, piece := range []Piece{PAWN, ROOK, KNIGHT, BISHOP, QUEEN, KING} {
+			c := NewCell(team, piece)
+			if c.IsEmpty() || c.GetTeam() != team || c.GetPiece() != piece {
+				t.Errorf("cell of %s %s decoded as %s %s", GetTeamName(team, LOWER), GetPieceName(piece, LOWER), GetTeamName(c.GetTeam(), LOWER), GetPieceName(c.GetPiece(), LOWER))
+			}
+			symbol := GetTeamName(team, SYMBOL) + " " + GetPieceName(piece, SYMBOL)
+			if c.AsSymbol() != symbol || GetCellFromSymbol(symbol) != c {
+				t.Errorf("cell %q does not round trip, got %q", symbol, c.AsSymbol())
+			}
+		}
+	}
+	if !EMPTY.IsEmpty() || EMPTY.GetTeam() != NEITHER || EMPTY.AsSymbol() != "   " {
+		t.Error("empty cell is not empty")
+	}
+}
+
+func TestPositionRendersBoard(t *testing.T) {
+	board := Board{}
+	board.Init()
+	position := NewPositionFromBoard(board)
+	if position.AsBoard() != board {
+		t.Error("position does not render as the board it was made of")
+	}
+	if position.GetCell(7, 4) != NewCell(WHITE, KING) || position.GetCell(0, 1) != NewCell(BLACK, KNIGHT) {
+		t.Error("position holds pieces on the wrong squares")
+	}
+	king := position.FindKing(BLACK)
+	if king.row != 0 || king.col != 4 {
+		t.Errorf("black King found at %d:%d", king.row, king.col)
+	}
+}
+
+func TestPositionExecute(t *testing.T) {
+	game, err := NewGameFromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, isValid, messages, _ := game.NewMove("O-O")
+	if !isValid {
+		t.Fatal(messages)
+	}
+	position := game.position
+	position.Execute(move)
+	if position.GetCell(7, 6) != NewCell(WHITE, KING) || position.GetCell(7, 5) != NewCell(WHITE, ROOK) {
+		t.Error("castling did not move both King and Rook")
+	}
+	if !position.GetCell(7, 4).IsEmpty() || !position.GetCell(7, 7).IsEmpty() {
+		t.Error("castling left King or Rook behind")
+	}
+	if game.position.GetCell(7, 4) != NewCell(WHITE, KING) {
+		t.Error("executing a copy changed the game position")
+	}
+}
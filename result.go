@@ -0,0 +1,34 @@
+package main
+
+// Result is the outcome of a chess game
+type Result int
+
+const (
+	// UNFINISHED is when the game has not ended, e.g. players quit
+	UNFINISHED Result = iota
+	// WHITEWINS is when white checkmates black, or black resigns
+	WHITEWINS
+	// BLACKWINS is when black checkmates white, or white resigns
+	BLACKWINS
+	// DRAW is when neither team wins, e.g. on stalemate
+	DRAW
+)
+
+// GetResultName returns the result as a chess score, e.g. "1-0"
+func GetResultName(result Result) string {
+	resultNames := map[Result]string{
+		UNFINISHED: "*",
+		WHITEWINS:  "1-0",
+		BLACKWINS:  "0-1",
+		DRAW:       "½-½",
+	}
+	return resultNames[result]
+}
+
+// GetWinResult returns the result of given team winning the game
+func GetWinResult(winner Team) Result {
+	if winner == WHITE {
+		return WHITEWINS
+	}
+	return BLACKWINS
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sanPattern matches a SAN move other than castling, capturing its piece,
+// origin column, origin row, capture mark, destination and promotion
+var sanPattern = regexp.MustCompile(`^([NBRQK])?([a-h])?([1-8])?(x)?([a-h][1-8])(?:=?([NBRQ]))?$`)
+
+// ParseSAN returns the legal move written in Standard Algebraic Notation
+// e.g. "Nf3", "exd5", "O-O", "Rae1" or "e8=Q+"
+// Check and annotation suffixes are ignored, and a missing capture mark is
+// tolerated. When the move is ambiguous or illegal, the error says why.
+func (g Game) ParseSAN(san string) (Move, error) {
+	teamName := GetTeamName(g.turn, LOWER)
+	trimmed := strings.TrimRight(strings.TrimSpace(san), "+#!?")
+
+	// castling, also accepted with zeros
+	castling := strings.Replace(trimmed, "0", "O", -1)
+	if castling == "O-O" || castling == "O-O-O" {
+		side := KINGSIDE
+		if castling == "O-O-O" {
+			side = QUEENSIDE
+		}
+		for _, m := range g.LegalMoves() {
+			if m.strategy == CASTLING && m.GetSide() == side {
+				return m, nil
+			}
+		}
+		return Move{}, fmt.Errorf("invalid; %s cannot castle %s", teamName, GetSideName(side))
+	}
+
+	parts := sanPattern.FindStringSubmatch(trimmed)
+	if parts == nil {
+		return Move{}, errors.New("invalid; example: 'd7 d6' or 'Nf3'")
+	}
+	piece := PAWN
+	if parts[1] != "" {
+		piece = GetPieceFromAlgebraic(parts[1])
+	}
+	fromCol, fromRow, isCapture := parts[2], parts[3], parts[4] != ""
+	destination, err := GetLocationFromAlgebraic(parts[5])
+	if err != nil {
+		return Move{}, err
+	}
+	promotion := PAWN
+	if parts[6] != "" {
+		promotion = GetPieceFromAlgebraic(parts[6])
+	}
+	pieceName := GetPieceName(piece, VERBOSE)
+
+	// pick moves of that piece to that square, from where the SAN says
+	matches := func(m Move) bool {
+		origin := GetAlgebraicFromLocation(m.GetLocation(BEFORE))
+		if m.GetLocation(AFTER) != destination || m.strategy == CASTLING {
+			return false
+		}
+		if g.position.GetSquare(m, BEFORE).piece != piece {
+			return false
+		}
+		if fromCol != "" && origin[:1] != fromCol {
+			return false
+		}
+		if piece == PAWN && fromCol == "" && m.GetLocation(BEFORE).col != destination.col {
+			return false
+		}
+		if fromRow != "" && origin[1:] != fromRow {
+			return false
+		}
+		return m.strategy != PROMOTION || promotion == PAWN || m.promotion == promotion
+	}
+	candidates := []Move{}
+	for _, m := range g.LegalMoves() {
+		if matches(m) {
+			candidates = append(candidates, m)
+		}
+	}
+
+	if len(candidates) == 0 {
+		for _, m := range g.PseudoLegalMoves() {
+			if matches(m) {
+				return Move{}, fmt.Errorf("invalid; %s leaves %s King in check", trimmed, teamName)
+			}
+		}
+		return Move{}, fmt.Errorf("invalid; no %s %s can move to %s", teamName, pieceName, parts[5])
+	}
+
+	m := candidates[0]
+	if m.strategy == PROMOTION && promotion == PAWN {
+		return Move{}, fmt.Errorf("invalid; choose a promotion piece, e.g. '%s=Q'", trimmed)
+	}
+	if m.strategy != PROMOTION && promotion != PAWN {
+		return Move{}, errors.New("invalid; only a pawn reaching the last row can be promoted")
+	}
+	if isCapture && g.position.GetSquare(m, AFTER).isEmpty && m.strategy != ENPASSANT {
+		return Move{}, fmt.Errorf("invalid; nothing to capture on %s", parts[5])
+	}
+
+	if len(candidates) > 1 {
+		options := []string{}
+		for _, candidate := range candidates {
+			options = append(options, g.GetSANWithoutSuffix(candidate))
+		}
+		return Move{}, fmt.Errorf("invalid; %s is ambiguous, it could be %s", trimmed, strings.Join(options, " or "))
+	}
+	return m, nil
+}
+
+// GetSAN returns a move on the board in Standard Algebraic Notation
+// A board has no history, so see Game.GetSAN for castling and en passant.
+func (b Board) GetSAN(m Move) string {
+	return NewGameFromBoard(b, m.team).GetSAN(m)
+}
+
+// GetSAN returns a move of the game in Standard Algebraic Notation
+// e.g. "Nf3", "exd5", "O-O" or "e8=Q+"
+// The move must be legal; the piece is disambiguated by column, row or both
+// only when another piece of the same kind could reach the same square.
+func (g Game) GetSAN(m Move) string {
+	return g.GetSANWithoutSuffix(m) + g.GetSANSuffix(m)
+}
+
+// GetSANWithoutSuffix returns a move in SAN, leaving out "+" or "#"
+// Finding the suffix means looking for the enemy's legal moves, which many
+// callers can do without.
+func (g Game) GetSANWithoutSuffix(m Move) string {
+	san := ""
+	if m.strategy == CASTLING {
+		san = "O-O"
+		if m.GetSide() == QUEENSIDE {
+			san = "O-O-O"
+		}
+	} else {
+		origin := m.GetLocation(BEFORE)
+		destination := m.GetLocation(AFTER)
+		piece := g.position.GetSquare(m, BEFORE).piece
+		isCapture := !g.position.GetSquare(m, AFTER).isEmpty || m.strategy == ENPASSANT
+
+		if piece == PAWN {
+			if isCapture {
+				san = string(GetAlgebraicFromLocation(origin)[0])
+			}
+		} else {
+			san = GetPieceName(piece, ALGEBRAIC) + g.GetDisambiguation(m, piece)
+		}
+		if isCapture {
+			san += "x"
+		}
+		san += GetAlgebraicFromLocation(destination)
+		if m.strategy == PROMOTION {
+			san += "=" + GetPieceName(m.promotion, ALGEBRAIC)
+		}
+	}
+
+	return san
+}
+
+// GetSANSuffix returns "+" if a move gives check, "#" if it checkmates
+func (g Game) GetSANSuffix(m Move) string {
+	next := g
+	next.Execute(m)
+	if !next.position.IsChecked(next.turn) {
+		return ""
+	}
+	if next.HasLegalMoves() {
+		return "+"
+	}
+	return "#"
+}
+
+// GetDisambiguation returns what tells the moved piece apart from others
+// of the same kind that could legally move to the same square
+// That is its column if enough, else its row, else both, e.g. "Nbd7".
+func (g Game) GetDisambiguation(m Move, piece Piece) string {
+	origin := m.GetLocation(BEFORE)
+	destination := m.GetLocation(AFTER)
+	sameCol, sameRow, others := false, false, false
+	for _, other := range g.LegalMoves() {
+		otherOrigin := other.GetLocation(BEFORE)
+		otherDestination := other.GetLocation(AFTER)
+		if otherDestination != destination || otherOrigin == origin {
+			continue
+		}
+		if g.position.ParseSquare(otherOrigin.row, otherOrigin.col).piece != piece {
+			continue
+		}
+		others = true
+		if otherOrigin.col == origin.col {
+			sameCol = true
+		}
+		if otherOrigin.row == origin.row {
+			sameRow = true
+		}
+	}
+
+	square := GetAlgebraicFromLocation(origin)
+	if !others {
+		return ""
+	} else if !sameCol {
+		return square[:1]
+	} else if !sameRow {
+		return square[1:]
+	}
+	return square
+}
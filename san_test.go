@@ -0,0 +1,183 @@
+package main
+
+import (
+	"testing"
+)
+
+// checkSAN plays commands on the game of given FEN, and compares the SAN of
+// the last one against expected
+func checkSAN(t *testing.T, fen string, commands []string, expected string) {
+	game, err := NewGameFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, command := range commands {
+		move, isValid, messages, _ := game.NewMove(command)
+		if !isValid {
+			t.Fatalf("move %s not valid: %v", command, messages)
+		}
+		if i == len(commands)-1 {
+			if san := game.GetSAN(move); san != expected {
+				t.Errorf("expected %s, got %s", expected, san)
+			}
+		}
+		game.Execute(move)
+	}
+}
+
+func TestSANPieceMove(t *testing.T) {
+	checkSAN(t, InitialFEN, []string{"g8 f6"}, "Nf3")
+}
+
+func TestSANPawnCapture(t *testing.T) {
+	checkSAN(t, InitialFEN, []string{"e7 e5", "d2 d4", "e5 d4"}, "exd5")
+}
+
+func TestSANEnPassant(t *testing.T) {
+	checkSAN(t, InitialFEN, []string{"e7 e5", "a2 a3", "e5 e4", "d2 d4", "e4 d3"}, "exd6")
+}
+
+func TestSANCastling(t *testing.T) {
+	fen := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	checkSAN(t, fen, []string{"e8 g8"}, "O-O")
+	checkSAN(t, fen, []string{"e8 c8"}, "O-O-O")
+}
+
+func TestSANDisambiguation(t *testing.T) {
+	checkSAN(t, "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", []string{"b8 d7"}, "Nbd2")
+	checkSAN(t, "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", []string{"a8 a6"}, "R1a3")
+	checkSAN(t, "4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1", []string{"a8 b7"}, "Qa1b2")
+}
+
+func TestSANPromotion(t *testing.T) {
+	checkSAN(t, "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", []string{"a2 a1 Q"}, "a8=Q+")
+	checkSAN(t, "1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1", []string{"a2 b1 K"}, "axb8=N")
+}
+
+func TestSANCheckmate(t *testing.T) {
+	checkSAN(t, InitialFEN, []string{"f7 f6", "e2 e4", "g7 g5", "d1 h5"}, "Qh4#")
+}
+
+// checkParseSAN parses san on the game of given FEN, and compares the move
+// as a command, or the error, against expected
+func checkParseSAN(t *testing.T, fen string, san string, expected string) {
+	game, err := NewGameFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := game.ParseSAN(san)
+	got := ""
+	if err != nil {
+		got = err.Error()
+	} else {
+		got = m.AsCommand()
+	}
+	if got != expected {
+		t.Errorf("%s: expected %q, got %q", san, expected, got)
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	checkParseSAN(t, InitialFEN, "Nf3", "g8 f6")
+	checkParseSAN(t, InitialFEN, "e4", "e7 e5")
+	checkParseSAN(t, "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", "exd5", "e5 d4")
+	checkParseSAN(t, "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", "ed5", "e5 d4")
+	checkParseSAN(t, "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3", "dxe3", "d5 e6")
+}
+
+func TestParseSANCastling(t *testing.T) {
+	fen := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	checkParseSAN(t, fen, "O-O", "e8 g8")
+	checkParseSAN(t, fen, "0-0-0", "e8 c8")
+	checkParseSAN(t, "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "O-O", "invalid; white cannot castle kingside")
+}
+
+func TestParseSANDisambiguation(t *testing.T) {
+	checkParseSAN(t, "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nbd2", "b8 d7")
+	checkParseSAN(t, "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", "Nd2", "invalid; Nd2 is ambiguous, it could be Nbd2 or Nfd2")
+	checkParseSAN(t, "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "R1a3", "a8 a6")
+	checkParseSAN(t, "4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1", "Qa1b2", "a8 b7")
+}
+
+func TestParseSANPromotion(t *testing.T) {
+	checkParseSAN(t, "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=Q+", "a2 a1 Q")
+	checkParseSAN(t, "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8N", "a2 a1 K")
+	checkParseSAN(t, "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8", "invalid; choose a promotion piece, e.g. 'a8=Q'")
+}
+
+func TestParseSANInvalid(t *testing.T) {
+	checkParseSAN(t, InitialFEN, "Nf4", "invalid; no white Knight can move to f4")
+	checkParseSAN(t, InitialFEN, "Nxf3", "invalid; nothing to capture on f3")
+	checkParseSAN(t, InitialFEN, "e4=Q", "invalid; only a pawn reaching the last row can be promoted")
+	checkParseSAN(t, InitialFEN, "hello", "invalid; example: 'd7 d6' or 'Nf3'")
+	checkParseSAN(t, "4k3/4r3/8/8/8/8/4B3/4K3 w - - 0 1", "Bd3", "invalid; Bd3 leaves white King in check")
+}
+
+func TestNewMoveSAN(t *testing.T) {
+	game := NewGame()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "O-O"} {
+		move, isValid, messages, _ := game.NewMove(san)
+		if !isValid {
+			t.Fatalf("SAN move %s not valid: %v", san, messages)
+		}
+		game.Execute(move)
+	}
+	if game.AsFEN() != "r1bqkbnr/1ppp1ppp/p1n5/1B2p3/4P3/5N2/PPPP1PPP/RNBQ1RK1 b kq - 1 4" {
+		t.Errorf("unexpected position %s", game.AsFEN())
+	}
+
+	// coordinate commands keep working alongside
+	_, isValid, _, _ := game.NewMove("b2 b3")
+	if !isValid {
+		t.Error("coordinate command not valid")
+	}
+}
+
+// checkSANRoundTrip checks that every legal move of the game parses back
+// from its SAN
+func checkSANRoundTrip(t *testing.T, game Game) {
+	for _, m := range game.LegalMoves() {
+		san := game.GetSAN(m)
+		parsed, err := game.ParseSAN(san)
+		if err != nil {
+			t.Errorf("%s: %s: %v", game.AsFEN(), san, err)
+		} else if parsed != m {
+			t.Errorf("%s: %s parsed as %s instead of %s", game.AsFEN(), san, parsed.AsCommand(), m.AsCommand())
+		}
+	}
+}
+
+func TestSANRoundTrip(t *testing.T) {
+	fens := []string{
+		InitialFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"4k3/8/8/8/8/Q7/8/Q1Q1K3 w - - 0 1",
+	}
+	for _, fen := range fens {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkSANRoundTrip(t, game)
+	}
+}
+
+func TestNewMoveSANMessage(t *testing.T) {
+	_, _, messages, _ := NewGame().NewMove("g8 f6")
+	if messages[0] != "MOVE: Nf3, white ○ Knight moved to f3" {
+		t.Errorf("unexpected message %s", messages[0])
+	}
+
+	game, err := NewGameFromFEN("4k3/P7/8/8/8/8/8/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, messages, _ = game.NewMove("a2 a1 Q")
+	if messages[0] != "PROMOTION: a8=Q+, white ○ Pawn moved to a8 and promoted to Queen" {
+		t.Errorf("unexpected message %s", messages[0])
+	}
+}
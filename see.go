@@ -0,0 +1,129 @@
+package main
+
+// seeValues are the values of the pieces in centipawns to static exchange
+// evaluation, by Piece
+// The King is worth more than everything else together, so that it never
+// captures onto a square the enemy still attacks.
+var seeValues = [6]int{
+	PAWN:   100,
+	ROOK:   500,
+	KNIGHT: 320,
+	BISHOP: 330,
+	QUEEN:  900,
+	KING:   20000,
+}
+
+// seeOrder are the pieces from the least valuable to the most, the order they
+// join an exchange in
+var seeOrder = []Piece{PAWN, KNIGHT, BISHOP, ROOK, QUEEN, KING}
+
+// SEE returns the net material gain of a move for its team, in centipawns,
+// once every capture on its destination has played out
+// Each team recaptures with its least valuable piece, and stops when going on
+// would lose more. Sliding pieces behind others, e.g. a Rook behind a Rook,
+// join as the square opens up. Pins and checks are not looked at.
+func (p Position) SEE(m Move) int {
+	from, to := GetMoveSquares(m)
+	piece := p.cells[from].GetPiece()
+	occupied := p.GetOccupied() &^ (Bitboard(1) << uint(from))
+
+	gain := 0
+	if m.strategy == ENPASSANT {
+		gain = seeValues[PAWN]
+		captured := m.GetEnPassantCapture()
+		occupied &^= NewBitboard(captured)
+	} else if cell := p.cells[to]; cell != EMPTY {
+		gain = seeValues[cell.GetPiece()]
+	}
+	if m.strategy == PROMOTION {
+		promotion := m.promotion
+		if promotion == PAWN {
+			promotion = QUEEN
+		}
+		gain += seeValues[promotion] - seeValues[PAWN]
+		piece = promotion
+	}
+	return p.Exchange(to, m.GetEnemy(), piece, gain, occupied)
+}
+
+// Exchange returns the net gain of the exchange on given square for the team
+// that made its first capture, worth gain, leaving piece on the square for
+// side to recapture, with occupied being the squares still occupied
+func (p Position) Exchange(square int, side Team, piece Piece, gain int, occupied Bitboard) int {
+	// gains are what the exchange is worth as it stops after each capture,
+	// for the team that made it
+	gains := [32]int{gain}
+	depth := 0
+	diagonals := p.pieces[WHITE][BISHOP] | p.pieces[BLACK][BISHOP] | p.pieces[WHITE][QUEEN] | p.pieces[BLACK][QUEEN]
+	straights := p.pieces[WHITE][ROOK] | p.pieces[BLACK][ROOK] | p.pieces[WHITE][QUEEN] | p.pieces[BLACK][QUEEN]
+	attackers := (p.GetAttackers(square, WHITE, occupied) | p.GetAttackers(square, BLACK, occupied)) & occupied
+	for depth < len(gains)-1 {
+		// the least valuable piece of side recaptures
+		capturer := Bitboard(0)
+		for _, next := range seeOrder {
+			if candidates := attackers & p.pieces[side][next]; candidates != 0 {
+				capturer = Bitboard(1) << uint(candidates.First())
+				depth++
+				gains[depth] = seeValues[piece] - gains[depth-1]
+				piece = next
+				break
+			}
+		}
+		if capturer == 0 {
+			break
+		}
+
+		// the capturer leaves its square, which may open the way to sliding
+		// pieces behind it
+		occupied &^= capturer
+		attackers |= GetBishopAttacks(square, occupied) & diagonals
+		attackers |= GetRookAttacks(square, occupied) & straights
+		attackers &= occupied
+		if side == WHITE {
+			side = BLACK
+		} else {
+			side = WHITE
+		}
+	}
+
+	// going back, each team only recaptures when that gains more than
+	// stopping, which leaves the enemy with what it gained so far
+	for ; depth > 0; depth-- {
+		if -gains[depth] < gains[depth-1] {
+			gains[depth-1] = -gains[depth]
+		}
+	}
+	return gains[0]
+}
+
+// GetHangingPieces returns the squares of the pieces of given team that the
+// enemy wins material by capturing, its King aside
+func (p Position) GetHangingPieces(team Team) []Location {
+	enemy := WHITE
+	if team == WHITE {
+		enemy = BLACK
+	}
+	hanging := []Location{}
+	occupied := p.GetOccupied()
+	for squares := p.teams[team] &^ p.pieces[team][KING]; squares != 0; {
+		square := squares.PopFirst()
+		attackers := p.GetAttackers(square, enemy, occupied)
+		if attackers == 0 {
+			continue
+		}
+
+		// the enemy starts with its least valuable attacker
+		for _, piece := range seeOrder {
+			if candidates := attackers & p.pieces[enemy][piece]; candidates != 0 {
+				from := candidates.First()
+				target := Location{row: square / 8, col: square % 8}
+				m := NewMoveFromLocations(p, enemy, Location{row: from / 8, col: from % 8}, target)
+				if p.SEE(m) > 0 {
+					hanging = append(hanging, target)
+				}
+				break
+			}
+		}
+	}
+	return hanging
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSEE(t *testing.T) {
+	for _, test := range []struct {
+		fen      string
+		san      string
+		expected int
+	}{
+		// the Knight is not defended
+		{"4k3/8/8/3n4/4P3/8/8/4K3 w - - 0 1", "exd5", 320},
+		// the pawn takes back the Rook
+		{"4k3/8/4p3/3p4/8/8/8/3RK3 w - - 0 1", "Rxd5", -400},
+		// the Rook behind the Rook takes back too, through the first one
+		{"3rk3/8/8/3p4/8/8/3R4/3RK3 w - - 0 1", "Rxd5", 100},
+		// the Bishop behind the Queen takes back the Rook, still losing 80
+		{"4r1k1/8/8/4n3/8/2Q5/1B6/6K1 w - - 0 1", "Qxe5", -80},
+		// the King cannot take back on a square the Bishop defends
+		{"6k1/5p2/8/8/2B5/8/8/5QK1 w - - 0 1", "Qxf7+", 100},
+		// en passant takes the pawn beside
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", 100},
+		// a quiet move to a square no enemy piece attacks neither wins nor loses
+		{"4k3/8/4p3/8/8/8/8/3NK3 w - - 0 1", "Nc3", 0},
+		// a quiet move to a square the pawn attacks loses the Rook
+		{"4k3/8/4p3/8/8/8/8/3RK3 w - - 0 1", "Rd5", -500},
+	} {
+		game, err := NewGameFromFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := game.ParseSAN(test.san)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if see := game.position.SEE(m); see != test.expected {
+			t.Errorf("%s: expected %s to gain %d, got %d", test.fen, test.san, test.expected, see)
+		}
+	}
+}
+
+func TestHangingPieces(t *testing.T) {
+	// the Knight on c3 falls to a pawn, the pawn on b2 is defended by the Rook
+	game, err := NewGameFromFEN("4k3/8/1q6/8/3p4/2N5/1P6/1R2K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hanging := game.position.GetHangingPieces(WHITE)
+	if len(hanging) != 1 || hanging[0] != (Location{row: 5, col: 2}) {
+		t.Errorf("expected the Knight on c3 to hang, got %v", hanging)
+	}
+	if hanging := game.position.GetHangingPieces(BLACK); len(hanging) != 0 {
+		t.Errorf("expected no black piece to hang, got %v", hanging)
+	}
+}
+
+func TestRankLosingCaptures(t *testing.T) {
+	// taking the pawn defended by a pawn loses the Queen
+	game, err := NewGameFromFEN("4k3/8/4p3/3p4/8/8/8/3QK3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(1, 0, 1, time.Now)
+	moves := game.LegalMoves()
+	ranks := engine.RankMoves(&game, moves, 1, Move{}, Move{})
+	for i := range moves {
+		PickMove(moves, ranks, i)
+	}
+	if san := game.GetSANWithoutSuffix(moves[len(moves)-1]); san != "Qxd5" {
+		t.Errorf("expected the losing capture last, got %s", san)
+	}
+}
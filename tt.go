@@ -0,0 +1,126 @@
+package main
+
+import (
+	"unsafe"
+)
+
+// Bound tells how a score stored in the transposition table relates to the
+// true score of the position
+type Bound uint8
+
+const (
+	// EXACT is when the score is the true score, found within the window
+	EXACT Bound = iota
+	// LOWERBOUND is when the search was cut off on a move at least that good,
+	// so the true score is at least the score
+	LOWERBOUND
+	// UPPERBOUND is when no move reached the window, so the true score is at
+	// most the score
+	UPPERBOUND
+)
+
+// TTEntry is what a search found about a position, stored by its hash
+type TTEntry struct {
+	hash  uint64
+	move  Move
+	score int32
+	depth int8
+	bound Bound
+	// age is the search the entry was stored in, older ones being replaced
+	// first
+	age uint8
+	// isUsed tells a stored entry from an empty slot
+	isUsed bool
+}
+
+// TranspositionTable stores what searches found about positions by their
+// Zobrist hash, so that positions reached again, by another order of moves or
+// in the next depth of iterative deepening, are not searched again
+// The table has a fixed number of entries, a power of two, and a position
+// always goes to the same one, replacing whatever stood there when the new
+// entry is worth more.
+type TranspositionTable struct {
+	entries []TTEntry
+	mask    uint64
+	age     uint8
+}
+
+// NewTranspositionTable returns an empty table taking at most given size in
+// megabytes, and at least one entry
+func NewTranspositionTable(megabytes int) *TranspositionTable {
+	count := uint64(megabytes) * 1024 * 1024 / uint64(unsafe.Sizeof(TTEntry{}))
+	size := uint64(1)
+	for size*2 <= count {
+		size *= 2
+	}
+	return &TranspositionTable{
+		entries: make([]TTEntry, size),
+		mask:    size - 1,
+	}
+}
+
+// Clear empties the table, e.g. between games
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.entries {
+		tt.entries[i] = TTEntry{}
+	}
+	tt.age = 0
+}
+
+// NewSearch ages the entries stored so far, to be replaced first
+func (tt *TranspositionTable) NewSearch() {
+	tt.age++
+}
+
+// Probe returns the entry of the position of given hash, and whether there is
+// one
+func (tt *TranspositionTable) Probe(hash uint64) (TTEntry, bool) {
+	entry := tt.entries[hash&tt.mask]
+	return entry, entry.isUsed && entry.hash == hash
+}
+
+// Store stores what a search to given depth found about the position of given
+// hash, ply plies away from the root
+// An entry of another position is only replaced when it is from an older
+// search or not deeper, the deeper ones having cost more to find.
+func (tt *TranspositionTable) Store(hash uint64, depth int, ply int, score int, bound Bound, move Move) {
+	entry := &tt.entries[hash&tt.mask]
+	if entry.isUsed && entry.hash != hash && entry.age == tt.age && int(entry.depth) > depth {
+		return
+	}
+	*entry = TTEntry{
+		hash:   hash,
+		move:   move,
+		score:  int32(ToTTScore(score, ply)),
+		depth:  int8(depth),
+		bound:  bound,
+		age:    tt.age,
+		isUsed: true,
+	}
+}
+
+// ToTTScore returns a score found ply plies away from the root as stored in
+// the table
+// A mate score counts the plies from the root, but the same position may be
+// reached at another ply, so the table counts them from the position instead.
+func ToTTScore(score int, ply int) int {
+	if score > MATE-maxPly {
+		return score + ply
+	}
+	if score < -MATE+maxPly {
+		return score - ply
+	}
+	return score
+}
+
+// FromTTScore returns a score stored in the table as found ply plies away
+// from the root, the other way around from ToTTScore
+func FromTTScore(score int, ply int) int {
+	if score > MATE-maxPly {
+		return score - ply
+	}
+	if score < -MATE+maxPly {
+		return score + ply
+	}
+	return score
+}
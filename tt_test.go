@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestTranspositionTable(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	if size := len(tt.entries); size&(size-1) != 0 || size*int(unsafe.Sizeof(TTEntry{})) > 1024*1024 {
+		t.Errorf("expected a power of two entries in a megabyte, got %d", size)
+	}
+
+	game := NewGame()
+	move, _, _, _ := game.NewMove("e4")
+	hash := game.GetHash()
+	if _, isFound := tt.Probe(hash); isFound {
+		t.Error("found an entry in an empty table")
+	}
+	tt.Store(hash, 3, 0, 25, LOWERBOUND, move)
+	entry, isFound := tt.Probe(hash)
+	if !isFound || entry.move != move || entry.score != 25 || entry.depth != 3 || entry.bound != LOWERBOUND {
+		t.Errorf("expected the stored entry back, got %+v", entry)
+	}
+
+	// another position on the same entry only replaces a deeper one once it
+	// is from an older search
+	other := hash + uint64(len(tt.entries))
+	tt.Store(other, 2, 0, 0, EXACT, Move{})
+	if _, isFound := tt.Probe(other); isFound {
+		t.Error("a shallower entry replaced a deeper one of the same search")
+	}
+	tt.NewSearch()
+	tt.Store(other, 2, 0, 0, EXACT, Move{})
+	if _, isFound := tt.Probe(other); !isFound {
+		t.Error("a new entry did not replace one of an older search")
+	}
+
+	tt.Clear()
+	if _, isFound := tt.Probe(other); isFound {
+		t.Error("found an entry after clearing the table")
+	}
+}
+
+func TestTTMateScores(t *testing.T) {
+	tt := NewTranspositionTable(1)
+
+	// mate in 3 plies from a position 2 plies from the root is mate in 5 from
+	// the root, but mate in 4 when reached 1 ply from the root
+	tt.Store(7, 4, 2, MATE-5, EXACT, Move{})
+	entry, _ := tt.Probe(7)
+	if FromTTScore(int(entry.score), 1) != MATE-4 {
+		t.Errorf("expected mate in 4 plies, got %d", MATE-FromTTScore(int(entry.score), 1))
+	}
+	tt.Store(8, 4, 3, -MATE+6, EXACT, Move{})
+	entry, _ = tt.Probe(8)
+	if FromTTScore(int(entry.score), 5) != -MATE+8 {
+		t.Errorf("expected to get mated in 8 plies, got %d", MATE+FromTTScore(int(entry.score), 5))
+	}
+	if ToTTScore(120, 9) != 120 || FromTTScore(120, 9) != 120 {
+		t.Error("expected scores other than mates to be stored as they are")
+	}
+}
+
+func TestEngineReusesTable(t *testing.T) {
+	game, err := NewGameFromFEN("r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(3, 0, 1, time.Now)
+	first := engine.Search(game, 0)
+	second := engine.Search(game, 0)
+	if second.nodes >= first.nodes || second.move != first.move {
+		t.Errorf("expected the second search to reuse the first, searched %d then %d nodes", first.nodes, second.nodes)
+	}
+	engine.NewGame()
+	if again := engine.Search(game, 0); again.nodes != first.nodes {
+		t.Errorf("expected a cleared table to search %d nodes again, got %d", first.nodes, again.nodes)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// pieceKeys are the Zobrist keys of each piece of each team on each square
+var pieceKeys [2][6][64]uint64
+
+// turnKey is the Zobrist key of black being the team to play next
+var turnKey uint64
+
+// castlingKeys are the Zobrist keys of each castling right
+var castlingKeys [2][2]uint64
+
+// enPassantKeys are the Zobrist keys of the column of the en passant square
+var enPassantKeys [8]uint64
+
+// zobristSeed makes the keys, and so every hash, the same on every run
+const zobristSeed = 1475
+
+func init() {
+	random := rand.New(rand.NewSource(zobristSeed))
+	for team := range pieceKeys {
+		for piece := range pieceKeys[team] {
+			for square := range pieceKeys[team][piece] {
+				pieceKeys[team][piece][square] = random.Uint64()
+			}
+		}
+	}
+	turnKey = random.Uint64()
+	for team := range castlingKeys {
+		for side := range castlingKeys[team] {
+			castlingKeys[team][side] = random.Uint64()
+		}
+	}
+	for col := range enPassantKeys {
+		enPassantKeys[col] = random.Uint64()
+	}
+}
+
+// GetCellKey returns the Zobrist key of a Cell on given square, 0 when empty
+func GetCellKey(c Cell, square int) uint64 {
+	if c == EMPTY {
+		return 0
+	}
+	return pieceKeys[c.GetTeam()][c.GetPiece()][square]
+}
+
+// GetHash returns the Zobrist hash of the game position
+// Two games share it when the same pieces stand on the same squares, the same
+// team plays next and the same castling and en passant captures are possible.
+// The position keeps the hash of its pieces up to date square by square, as
+// moves are made and unmade, so only the rest is added here.
+func (g Game) GetHash() uint64 {
+	return g.position.hash ^ g.GetStateKey()
+}
+
+// ComputeHash computes the Zobrist hash of the game position from scratch,
+// the way Make and Unmake keep it up to date move by move
+func (g Game) ComputeHash() uint64 {
+	hash := uint64(0)
+	for square, c := range g.position.cells {
+		hash ^= GetCellKey(c, square)
+	}
+	return hash ^ g.GetStateKey()
+}
+
+// GetStateKey returns the part of the Zobrist hash that the pieces alone
+// cannot tell: the team to play next, castling rights and en passant
+// The en passant column only counts when a pawn can actually capture there,
+// so that positions differing in nothing else share a hash.
+func (g Game) GetStateKey() uint64 {
+	key := uint64(0)
+	if g.turn == BLACK {
+		key ^= turnKey
+	}
+	for team := range castlingKeys {
+		for side := range castlingKeys[team] {
+			if g.castling[team][side] {
+				key ^= castlingKeys[team][side]
+			}
+		}
+	}
+	if g.hasEnPassant {
+		square := g.enPassant.row*8 + g.enPassant.col
+		if pawnAttacks[g.GetEnemy()][square]&g.position.pieces[g.turn][PAWN] != 0 {
+			key ^= enPassantKeys[g.enPassant.col]
+		}
+	}
+	return key
+}
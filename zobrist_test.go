@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+)
+
+// playMoves plays given moves in SAN on the game, failing on an invalid one
+func playMoves(t *testing.T, game Game, sans ...string) Game {
+	for _, san := range sans {
+		move, isValid, messages, _ := game.NewMove(san)
+		if !isValid {
+			t.Fatalf("move %s not valid: %v", san, messages)
+		}
+		game.Make(move)
+	}
+	return game
+}
+
+func TestHashTransposition(t *testing.T) {
+	first := playMoves(t, NewGame(), "Nf3", "Nf6", "Nc3")
+	second := playMoves(t, NewGame(), "Nc3", "Nf6", "Nf3")
+	if first.GetHash() != second.GetHash() {
+		t.Error("same position reached by different move orders has different hashes")
+	}
+	fromFEN, err := NewGameFromFEN(first.AsFEN())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetHash() != fromFEN.GetHash() {
+		t.Error("hash kept move by move differs from the one of the same FEN")
+	}
+}
+
+func TestHashState(t *testing.T) {
+	hashes := map[uint64]string{}
+	for _, fen := range []string{
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQk - 0 1",
+		"r3k2r/8/8/8/8/8/8/R3K2R w - - 0 1",
+	} {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other, ok := hashes[game.GetHash()]; ok {
+			t.Errorf("%s has the same hash as %s", fen, other)
+		}
+		hashes[game.GetHash()] = fen
+	}
+}
+
+func TestHashEnPassant(t *testing.T) {
+	hashOf := func(fen string) uint64 {
+		game, err := NewGameFromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return game.GetHash()
+	}
+
+	// no black pawn can capture on e3, so the en passant square changes nothing
+	if hashOf("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1") != hashOf("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1") {
+		t.Error("en passant square without a capture changes the hash")
+	}
+
+	// a black pawn on d4 can, so it does
+	if hashOf("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1") == hashOf("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1") {
+		t.Error("en passant capture does not change the hash")
+	}
+}
+
+// checkHash makes every legal move down to given depth, and fails unless the
+// hash kept move by move matches the one computed from scratch
+func checkHash(t *testing.T, game Game, depth int) {
+	if game.GetHash() != game.ComputeHash() {
+		t.Fatalf("hash of %s is out of date", game.AsFEN())
+	}
+	if depth == 0 {
+		return
+	}
+	for _, m := range game.LegalMoves() {
+		u := game.Make(m)
+		checkHash(t, game, depth-1)
+		game.Unmake(u)
+	}
+}
+
+func TestHashMakeUnmake(t *testing.T) {
+	game, err := NewGameFromFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHash(t, game, 3)
+}